@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"sync"
+)
+
+// barrier splits reported events into epochs, events belonging to an epoch
+// are only sent once every event in the preceding epochs has been sent.
+type barrier struct {
+	mu sync.Mutex
+	// The events in the current epoch.
+	pending *sync.WaitGroup
+	// Closed once the current epoch is allowed to send.
+	gate chan struct{}
+}
+
+func newBarrier() *barrier {
+	gate := make(chan struct{})
+	close(gate)
+
+	return &barrier{
+		pending: &sync.WaitGroup{},
+		gate:    gate,
+	}
+}
+
+// enter adds an event to the current epoch. The event must not be sent until
+// the returned gate is closed and done must be called once the event has been
+// sent (or dropped).
+func (b *barrier) enter() (gate <-chan struct{}, done func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending.Add(1)
+
+	return b.gate, b.pending.Done
+}
+
+// next starts a new epoch, returning a channel that is closed once every
+// event in the preceding epochs has been sent.
+func (b *barrier) next() <-chan struct{} {
+	b.mu.Lock()
+	prev, pending := b.gate, b.pending
+	gate := make(chan struct{})
+	b.pending = &sync.WaitGroup{}
+	b.gate = gate
+	b.mu.Unlock()
+
+	// Events in the preceding epoch only start once their own gate is
+	// closed, so wait for that too (the preceding epoch may be empty).
+	go func() {
+		<-prev
+		pending.Wait()
+		close(gate)
+	}()
+
+	return gate
+}
@@ -0,0 +1,239 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Barrier(t *testing.T) {
+	const preBarrierEvents = 3
+
+	var mu sync.Mutex
+	var log []string
+
+	preBarrierReceived := make(chan struct{}, preBarrierEvents)
+	release := make(chan struct{})
+
+	// Start a mock telemetry server that holds on to the events preceding
+	// the barrier until released.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var event v1alpha1.TelemetryEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		if strings.HasPrefix(event.Name, "Pre") {
+			preBarrierReceived <- struct{}{}
+			<-release
+		}
+
+		mu.Lock()
+		log = append(log, event.Name)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter, every event needs to be in-flight at
+	// once.
+	conf := telemetry.Configuration{
+		BaseURL:                   server.URL,
+		ReservedHighPrioritySlots: -1,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for i := 0; i < preBarrierEvents; i++ {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "Pre"})
+	}
+
+	for i := 0; i < preBarrierEvents; i++ {
+		select {
+		case <-preBarrierReceived:
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for telemetry event")
+		}
+	}
+
+	barrierErr := make(chan error, 1)
+	go func() {
+		barrierErr <- reporter.Barrier(ctx)
+	}()
+
+	// Give the barrier a chance to be established.
+	time.Sleep(10 * time.Millisecond)
+
+	// Concurrently report events after the barrier.
+	var producer sync.WaitGroup
+	producer.Add(1)
+	go func() {
+		defer producer.Done()
+
+		for i := 0; i < 10; i++ {
+			reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "Post"})
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	producer.Wait()
+
+	select {
+	case <-barrierErr:
+		t.Fatal("Barrier returned before the preceding events were sent")
+	default:
+	}
+
+	close(release)
+
+	select {
+	case err := <-barrierErr:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for barrier")
+	}
+
+	// Shutdown the reporter to ensure all events are sent.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, log, preBarrierEvents+10)
+	for i, name := range log {
+		if i < preBarrierEvents {
+			assert.Equal(t, "Pre", name)
+		} else {
+			assert.Equal(t, "Post", name)
+		}
+	}
+}
+
+func TestReporter_BarrierConsecutive(t *testing.T) {
+	var mu sync.Mutex
+	var log []string
+
+	received := make(chan struct{}, 1)
+	release := make(chan struct{})
+
+	// Start a mock telemetry server that holds on to the slow event until
+	// released.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var event v1alpha1.TelemetryEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		if event.Name == "Slow" {
+			received <- struct{}{}
+			<-release
+		}
+
+		mu.Lock()
+		log = append(log, event.Name)
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	var releaseOnce sync.Once
+	releaseSlow := func() {
+		releaseOnce.Do(func() { close(release) })
+	}
+	t.Cleanup(releaseSlow)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "Slow"})
+
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("Timeout waiting for telemetry event")
+	}
+
+	barrierCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	t.Cleanup(cancel)
+
+	// The first barrier times out, as the slow event is still in flight.
+	require.ErrorIs(t, reporter.Barrier(barrierCtx), context.DeadlineExceeded)
+
+	// The second barrier's (empty) epoch must still wait for the slow event.
+	barrierCtx, cancel = context.WithTimeout(ctx, 50*time.Millisecond)
+	t.Cleanup(cancel)
+
+	require.ErrorIs(t, reporter.Barrier(barrierCtx), context.DeadlineExceeded)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "After"})
+
+	// Give the later event a chance to (incorrectly) overtake the slow one.
+	time.Sleep(50 * time.Millisecond)
+
+	releaseSlow()
+
+	// Shutdown the reporter to ensure all events are sent.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"Slow", "After"}, log)
+}
+
+func TestReporter_BarrierTimeout(t *testing.T) {
+	release := make(chan struct{})
+
+	// Start a mock telemetry server that never completes until released.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	reporter := telemetry.NewReporter(context.Background(), slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "Pre"})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	t.Cleanup(cancel)
+
+	require.ErrorIs(t, reporter.Barrier(ctx), context.DeadlineExceeded)
+
+	close(release)
+
+	require.NoError(t, reporter.Shutdown(context.Background()))
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+const (
+	// The default maximum duration an event is buffered before being sent.
+	defaultBatchInterval = 5 * time.Second
+)
+
+// batchedEvent is an event waiting to be sent as part of a batch.
+type batchedEvent struct {
+	event *v1alpha1.TelemetryEvent
+	// The barrier gate that must be open before the event is sent.
+	gate <-chan struct{}
+	// Called once the event has been sent (or dropped).
+	done func()
+	// The number of previous attempts to send the event.
+	attempts int
+	// The marshaled size of the event, if MaxBatchBytes is set.
+	bytes int
+}
+
+// doneAll marks every event in the batch as done.
+func doneAll(batch []batchedEvent) {
+	for _, e := range batch {
+		e.done()
+	}
+}
+
+// batcher buffers events and sends them in batches, either when the batch is
+// full or the batch interval has elapsed. Events within a batch are kept in
+// the order they were added, but batches may be sent concurrently, so the
+// order across batches is best-effort.
+type batcher struct {
+	size     int
+	maxBytes int
+	marshal  func(*v1alpha1.TelemetryEvent) ([]byte, error)
+	send     func([]batchedEvent)
+	mu       sync.Mutex
+	pending  []batchedEvent
+	// The total marshaled size of the pending events, if maxBytes is set.
+	pendingBytes int
+	stopped      bool
+	stopCh       chan struct{}
+	stopOnce     sync.Once
+}
+
+// newBatcher creates a batcher that sends a batch once it holds size events,
+// or (if maxBytes is positive) once adding another event would take the total
+// marshaled size of the batch over maxBytes.
+func newBatcher(size, maxBytes int, interval time.Duration, marshal func(*v1alpha1.TelemetryEvent) ([]byte, error), send func([]batchedEvent)) *batcher {
+	if interval <= 0 {
+		interval = defaultBatchInterval
+	}
+
+	if marshal == nil {
+		marshal = func(event *v1alpha1.TelemetryEvent) ([]byte, error) {
+			return json.Marshal(event)
+		}
+	}
+
+	b := &batcher{
+		size:     size,
+		maxBytes: maxBytes,
+		marshal:  marshal,
+		send:     send,
+		stopCh:   make(chan struct{}),
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-b.stopCh:
+				return
+			case <-ticker.C:
+				b.flush()
+			}
+		}
+	}()
+
+	return b
+}
+
+// add appends an event to the current batch, sending the batch if it is full.
+// If the batcher has been stopped, the event is not added and false is returned.
+func (b *batcher) add(e batchedEvent) bool {
+	var batches [][]batchedEvent
+
+	if b.maxBytes > 0 && e.bytes == 0 {
+		// Events that fail to marshal will fail to send anyway.
+		if eventJSON, err := b.marshal(e.event); err == nil {
+			e.bytes = len(eventJSON)
+		}
+	}
+
+	b.mu.Lock()
+	if b.stopped {
+		b.mu.Unlock()
+		return false
+	}
+
+	// Batches never span a barrier, otherwise the batch would be waiting on
+	// itself to be sent.
+	if len(b.pending) > 0 && b.pending[len(b.pending)-1].gate != e.gate {
+		batches = append(batches, b.take())
+	}
+
+	// Send the batch before the event would take it over the size limit.
+	if b.maxBytes > 0 && len(b.pending) > 0 && b.pendingBytes+e.bytes > b.maxBytes {
+		batches = append(batches, b.take())
+	}
+
+	b.pending = append(b.pending, e)
+	b.pendingBytes += e.bytes
+	if len(b.pending) >= b.size || (b.maxBytes > 0 && b.pendingBytes >= b.maxBytes) {
+		batches = append(batches, b.take())
+	}
+	b.mu.Unlock()
+
+	for _, batch := range batches {
+		b.send(batch)
+	}
+
+	return true
+}
+
+// flush sends the current batch, if there is one.
+func (b *batcher) flush() {
+	b.mu.Lock()
+	batch := b.take()
+	b.mu.Unlock()
+
+	if len(batch) > 0 {
+		b.send(batch)
+	}
+}
+
+// stop stops the batcher, returning any events that have not yet been sent.
+func (b *batcher) stop() []batchedEvent {
+	b.stopOnce.Do(func() {
+		close(b.stopCh)
+	})
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stopped = true
+
+	return b.take()
+}
+
+// take removes and returns the current batch, b.mu must be held.
+func (b *batcher) take() []batchedEvent {
+	batch := b.pending
+	b.pending = nil
+	b.pendingBytes = 0
+	return batch
+}
+
+// validateBatchEncoding checks that batches can be encoded with the configured
+// content type, which is framed as JSON unless a BatchMarshaler is set.
+func validateBatchEncoding(conf Configuration) error {
+	if conf.BatchSize <= 1 || conf.ContentType == "" || v1alpha1.IsJSONContentType(conf.ContentType) {
+		return nil
+	}
+
+	if conf.StreamBatches {
+		return fmt.Errorf("invalid content type %q: streamed batches require a JSON content type", conf.ContentType)
+	}
+
+	if conf.BatchMarshaler == nil {
+		return fmt.Errorf("invalid content type %q: batching requires a BatchMarshaler", conf.ContentType)
+	}
+
+	return nil
+}
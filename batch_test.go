@@ -0,0 +1,528 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Batching(t *testing.T) {
+	// Start a mock telemetry server.
+	server, batchCh := mockBatchTelemetryServer(t, nil)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		BatchSize:     2,
+		BatchInterval: time.Hour,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for i := 0; i < 3; i++ {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: fmt.Sprintf("Event%d", i),
+		})
+	}
+
+	// The first batch is sent as soon as it is full.
+	batch := receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"Event0", "Event1"}, eventNames(batch))
+
+	// The remaining events are sent on shutdown.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	batch = receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"Event2"}, eventNames(batch))
+}
+
+func TestReporter_MaxBatchBytes(t *testing.T) {
+	// Start a mock telemetry server.
+	server, batchCh := mockBatchTelemetryServer(t, nil)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		BatchSize:     100,
+		MaxBatchBytes: 4096,
+		BatchInterval: time.Hour,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// Each event is a little over 1KiB once marshaled.
+	for i := 0; i < 5; i++ {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name:    fmt.Sprintf("Event%d", i),
+			Message: strings.Repeat("x", 1024),
+		})
+	}
+
+	// The batch is sent before the fourth event would take it over the byte
+	// limit, long before the count limit is reached.
+	batch := receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"Event0", "Event1", "Event2"}, eventNames(batch))
+
+	eventsJSON, err := json.Marshal(batch)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(eventsJSON), conf.MaxBatchBytes)
+
+	// The remaining events are sent on shutdown.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	batch = receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"Event3", "Event4"}, eventNames(batch))
+}
+
+func TestReporter_BatchingOrder(t *testing.T) {
+	const batchSize = 50
+
+	// Start a mock telemetry server.
+	server, batchCh := mockBatchTelemetryServer(t, nil)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		BatchSize:     batchSize,
+		BatchInterval: time.Hour,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// Report a numbered sequence from many goroutines, serialized by a mutex.
+	var mu sync.Mutex
+	next := 0
+	var want []string
+
+	var senders sync.WaitGroup
+	for i := 0; i < batchSize; i++ {
+		senders.Add(1)
+		go func() {
+			defer senders.Done()
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			name := fmt.Sprintf("Event%d", next)
+			next++
+			want = append(want, name)
+
+			reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+				Name: name,
+			})
+		}()
+	}
+	senders.Wait()
+
+	batch := receiveBatch(t, batchCh)
+	assert.Equal(t, want, eventNames(batch))
+
+	// Timestamps should also be in order.
+	for i := 1; i < len(batch); i++ {
+		assert.False(t, batch[i].Timestamp.Before(*batch[i-1].Timestamp))
+	}
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_BatchingBarrier(t *testing.T) {
+	// Start a mock telemetry server.
+	server, batchCh := mockBatchTelemetryServer(t, nil)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		BatchSize:     10,
+		BatchInterval: time.Hour,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	// The barrier should not wait for the batch interval to elapse.
+	barrierCtx, cancel := context.WithTimeout(ctx, time.Second)
+	t.Cleanup(cancel)
+
+	require.NoError(t, reporter.Barrier(barrierCtx))
+
+	batch := receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"TestEvent"}, eventNames(batch))
+
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_StreamBatches(t *testing.T) {
+	batchCh := make(chan []*v1alpha1.TelemetryEvent, 1)
+
+	// Start a mock telemetry server that decodes each streamed line.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		require.Equal(t, "/v1alpha1/events/stream", r.URL.Path)
+		require.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+
+		var events []*v1alpha1.TelemetryEvent
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var event v1alpha1.TelemetryEvent
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+
+			events = append(events, &event)
+		}
+		require.NoError(t, scanner.Err())
+
+		batchCh <- events
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		BatchSize:     3,
+		BatchInterval: time.Hour,
+		StreamBatches: true,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for i := 0; i < 3; i++ {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: fmt.Sprintf("Event%d", i),
+		})
+	}
+
+	batch := receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"Event0", "Event1", "Event2"}, eventNames(batch))
+
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_ValidateBatchResponse(t *testing.T) {
+	var requests int
+
+	// Start a mock telemetry server that rejects the second event of the
+	// first batch.
+	server, batchCh := mockBatchTelemetryServer(t, func(w http.ResponseWriter, events []*v1alpha1.TelemetryEvent) {
+		requests++
+
+		var resp batchResponse
+		if requests == 1 {
+			resp.Rejected = []rejectedEvent{{Index: 1, Reason: "invalid event"}}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	})
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		BatchSize:     3,
+		BatchInterval: 50 * time.Millisecond,
+		MaxRetries:    1,
+		ValidateBatchResponse: func(r *http.Response, events []*v1alpha1.TelemetryEvent) error {
+			var resp batchResponse
+			if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+				return err
+			}
+
+			if len(resp.Rejected) == 0 {
+				return nil
+			}
+
+			rejectedErr := &v1alpha1.RejectedEventsError{Rejected: map[int]string{}}
+			for _, rejected := range resp.Rejected {
+				rejectedErr.Rejected[rejected.Index] = rejected.Reason
+			}
+
+			return rejectedErr
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for _, name := range []string{"First", "Second", "Third"} {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: name,
+		})
+	}
+
+	batch := receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"First", "Second", "Third"}, eventNames(batch))
+
+	// Only the rejected event should be retried.
+	batch = receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"Second"}, eventNames(batch))
+
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	select {
+	case batch := <-batchCh:
+		t.Fatalf("Expected no further batches, but got: %v", eventNames(batch))
+	default:
+	}
+}
+
+func TestReporter_PartialSuccess(t *testing.T) {
+	var requests int
+
+	// Start a mock telemetry server that rejects the first and last events of
+	// the first batch, and rejects the last event again when it is retried.
+	server, batchCh := mockBatchTelemetryServer(t, func(w http.ResponseWriter, events []*v1alpha1.TelemetryEvent) {
+		requests++
+
+		var resp v1alpha1.BatchResponse
+		switch requests {
+		case 1:
+			resp.Rejected = []v1alpha1.RejectedEvent{
+				{Index: 0, Reason: "rate limited"},
+				{Index: 2, Reason: "invalid event"},
+			}
+		case 2:
+			resp.Rejected = []v1alpha1.RejectedEvent{{Index: 1, Reason: "invalid event"}}
+		}
+
+		w.WriteHeader(http.StatusMultiStatus)
+		require.NoError(t, json.NewEncoder(w).Encode(resp))
+	})
+	t.Cleanup(server.Close)
+
+	var mu sync.Mutex
+	var dropped []*v1alpha1.TelemetryEvent
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		BatchSize:     3,
+		BatchInterval: 50 * time.Millisecond,
+		MaxRetries:    1,
+		OnDrop: func(event *v1alpha1.TelemetryEvent, reason telemetry.DropReason) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, telemetry.DropReasonRejected, reason)
+			dropped = append(dropped, event)
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for _, name := range []string{"First", "Second", "Third"} {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: name,
+		})
+	}
+
+	batch := receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"First", "Second", "Third"}, eventNames(batch))
+
+	// Only the rejected events should be retried.
+	batch = receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"First", "Third"}, eventNames(batch))
+
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	select {
+	case batch := <-batchCh:
+		t.Fatalf("Expected no further batches, but got: %v", eventNames(batch))
+	default:
+	}
+
+	// The event rejected again is dropped, with the reason it was rejected.
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, dropped, 1)
+	assert.Equal(t, "Third", dropped[0].Name)
+	assert.Equal(t, "invalid event", dropped[0].Values["telemetry_rejected_reason"])
+
+	stats := reporter.Stats()
+	assert.Equal(t, uint64(2), stats.Reported)
+	assert.Equal(t, uint64(1), stats.Dropped[telemetry.DropReasonRejected])
+}
+
+func TestReporter_ValidateResponse(t *testing.T) {
+	// Start a mock telemetry server that accepts the request but reports
+	// that the event was not processed.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"accepted":false}`))
+	}))
+	t.Cleanup(server.Close)
+
+	errNotAccepted := errors.New("event not accepted")
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		ValidateResponse: func(r *http.Response) error {
+			var resp struct {
+				Accepted bool `json:"accepted"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&resp); err != nil {
+				return err
+			}
+
+			if !resp.Accepted {
+				return errNotAccepted
+			}
+
+			return nil
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	err := reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+	require.ErrorIs(t, err, errNotAccepted)
+
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+type batchResponse struct {
+	Rejected []rejectedEvent `json:"rejected,omitempty"`
+}
+
+func TestReporter_BatchEncoding(t *testing.T) {
+	marshalBatch := func([]*v1alpha1.TelemetryEvent) ([]byte, error) {
+		return nil, nil
+	}
+
+	tests := []struct {
+		name    string
+		conf    telemetry.Configuration
+		wantErr bool
+	}{
+		{
+			name: "JSON",
+			conf: telemetry.Configuration{ContentType: "application/vnd.example+json", StreamBatches: true},
+		},
+		{
+			name: "Binary",
+			conf: telemetry.Configuration{ContentType: "application/msgpack", BatchMarshaler: marshalBatch},
+		},
+		{
+			name:    "Binary Without BatchMarshaler",
+			conf:    telemetry.Configuration{ContentType: "application/msgpack"},
+			wantErr: true,
+		},
+		{
+			name:    "Binary Streamed",
+			conf:    telemetry.Configuration{ContentType: "application/msgpack", BatchMarshaler: marshalBatch, StreamBatches: true},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.conf.BaseURL = "http://telemetry.invalid"
+			tt.conf.BatchSize = 10
+
+			ctx := context.Background()
+			reporter, err := telemetry.NewReporterWithError(ctx, slog.Default(), tt.conf)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			// Shutdown the reporter to ensure graceful exit.
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+	}
+}
+
+type rejectedEvent struct {
+	Index  int    `json:"index"`
+	Reason string `json:"reason"`
+}
+
+func mockBatchTelemetryServer(t *testing.T, respond func(w http.ResponseWriter, events []*v1alpha1.TelemetryEvent)) (*httptest.Server, chan []*v1alpha1.TelemetryEvent) {
+	batchCh := make(chan []*v1alpha1.TelemetryEvent, 8)
+
+	// Create a mock server to handle incoming batches of telemetry events.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		require.Equal(t, "/v1alpha1/events/batch", r.URL.Path)
+		require.Equal(t, "POST", r.Method)
+
+		var events []*v1alpha1.TelemetryEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&events))
+
+		batchCh <- events
+
+		if respond != nil {
+			respond(w, events)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	return server, batchCh
+}
+
+func receiveBatch(t *testing.T, batchCh chan []*v1alpha1.TelemetryEvent) []*v1alpha1.TelemetryEvent {
+	t.Helper()
+
+	select {
+	case batch := <-batchCh:
+		return batch
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout waiting for telemetry batch")
+		return nil
+	}
+}
+
+func eventNames(events []*v1alpha1.TelemetryEvent) []string {
+	names := make([]string, len(events))
+	for i, event := range events {
+		names[i] = event.Name
+	}
+	return names
+}
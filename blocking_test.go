@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_BlockTimeout(t *testing.T) {
+	var received atomic.Int64
+
+	// Start a slow mock telemetry server.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		time.Sleep(10 * time.Millisecond)
+
+		received.Add(1)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	t.Run("Blocking", func(t *testing.T) {
+		received.Store(0)
+
+		conf := telemetry.Configuration{
+			BaseURL:      server.URL,
+			BlockTimeout: 5 * time.Second,
+		}
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		const events = telemetry.MaxConcurrentReports * 4
+		for i := 0; i < events; i++ {
+			reporter.ReportInfo("TestEvent", nil)
+		}
+
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		// No events should be dropped, even though the server can't keep up.
+		assert.Equal(t, int64(events), received.Load())
+		assert.Zero(t, reporter.Stats().TotalDropped())
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		received.Store(0)
+
+		conf := telemetry.Configuration{
+			BaseURL:      server.URL,
+			BlockTimeout: time.Millisecond,
+		}
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		for i := 0; i < telemetry.MaxConcurrentReports*4; i++ {
+			reporter.ReportInfo("TestEvent", nil)
+		}
+
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		// Events are dropped once the timeout elapses.
+		assert.NotZero(t, reporter.Stats().Dropped[telemetry.DropReasonQueueFull])
+	})
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// The value key used to attach the trail of recent events to error events.
+const breadcrumbsValueKey = "breadcrumbs"
+
+// Breadcrumb is a summary of a recently reported event.
+type Breadcrumb struct {
+	// Name is the name of the event.
+	Name string `json:"name"`
+	// Timestamp is when the event occurred.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// breadcrumbs is a bounded ring buffer of recently reported events.
+type breadcrumbs struct {
+	mu   sync.Mutex
+	buf  []Breadcrumb
+	next int
+	full bool
+}
+
+// newBreadcrumbs creates a ring buffer holding the last size events, or nil
+// if size is not positive (a nil buffer records nothing).
+func newBreadcrumbs(size int) *breadcrumbs {
+	if size <= 0 {
+		return nil
+	}
+
+	return &breadcrumbs{buf: make([]Breadcrumb, size)}
+}
+
+// record adds the event to the buffer. If it is an error event, the trail of
+// the events preceding it is first attached to its values, as a JSON array of
+// breadcrumbs (oldest first) under the "breadcrumbs" key.
+func (b *breadcrumbs) record(event *v1alpha1.TelemetryEvent, now time.Time) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event.Kind == v1alpha1.TelemetryEventKindError {
+		if trail := b.trail(); len(trail) > 0 {
+			// Can't fail, breadcrumbs are always marshalable.
+			trailJSON, _ := json.Marshal(trail)
+			setValue(event, breadcrumbsValueKey, string(trailJSON))
+		}
+	}
+
+	b.buf[b.next] = Breadcrumb{Name: event.Name, Timestamp: now}
+	b.next = (b.next + 1) % len(b.buf)
+	if b.next == 0 {
+		b.full = true
+	}
+}
+
+// trail returns the buffered breadcrumbs, oldest first, b.mu must be held.
+func (b *breadcrumbs) trail() []Breadcrumb {
+	if !b.full {
+		return append([]Breadcrumb(nil), b.buf[:b.next]...)
+	}
+
+	return append(append([]Breadcrumb(nil), b.buf[b.next:]...), b.buf[:b.next]...)
+}
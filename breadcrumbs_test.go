@@ -0,0 +1,101 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Breadcrumbs(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:        server.URL,
+		MaxBreadcrumbs: 2,
+		Clock:          clock,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for _, name := range []string{"First", "Second", "Third"} {
+		clock.Advance(time.Second)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: name})
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, name, event.Name)
+		assert.NotContains(t, event.Values, "breadcrumbs")
+	}
+
+	clock.Advance(time.Second)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Kind: v1alpha1.TelemetryEventKindError,
+		Name: "Failure",
+	})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "Failure", event.Name)
+
+	// Only the most recent events are remembered.
+	var breadcrumbs []telemetry.Breadcrumb
+	require.NoError(t, json.Unmarshal([]byte(event.Values["breadcrumbs"]), &breadcrumbs))
+	assert.Equal(t, []telemetry.Breadcrumb{
+		{Name: "Second", Timestamp: start.Add(2 * time.Second)},
+		{Name: "Third", Timestamp: start.Add(3 * time.Second)},
+	}, breadcrumbs)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_BreadcrumbsDisabled(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "First"})
+	_ = receiveEvent(t, eventCh)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Kind: v1alpha1.TelemetryEventKindError,
+		Name: "Failure",
+	})
+
+	event := receiveEvent(t, eventCh)
+	assert.NotContains(t, event.Values, "breadcrumbs")
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"os"
+	"strings"
+)
+
+// Environment variables set by common CI systems.
+var ciEnvNames = []string{
+	"CI",
+	"CONTINUOUS_INTEGRATION",
+	"GITHUB_ACTIONS",
+	"GITLAB_CI",
+	"JENKINS_URL",
+	"CIRCLECI",
+	"TRAVIS",
+	"BUILDKITE",
+	"DRONE",
+	"APPVEYOR",
+	"TEAMCITY_VERSION",
+	"TF_BUILD",
+	"BITBUCKET_BUILD_NUMBER",
+	"CODEBUILD_BUILD_ID",
+}
+
+// runningInCI returns true if the process appears to be running in a CI environment.
+func runningInCI() bool {
+	for _, name := range ciEnvNames {
+		value := strings.ToLower(os.Getenv(name))
+		if value != "" && value != "false" && value != "0" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	// The tests are frequently run in CI, which would otherwise disable
+	// telemetry reporting.
+	for _, name := range telemetry.CIEnvNames {
+		os.Unsetenv(name)
+	}
+
+	os.Exit(m.Run())
+}
+
+func TestReporter_CI(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	for _, name := range telemetry.CIEnvNames {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, "true")
+
+			t.Run("Disabled", func(t *testing.T) {
+				reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+					BaseURL: server.URL,
+				})
+				t.Cleanup(func() {
+					require.NoError(t, reporter.Shutdown(ctx))
+				})
+
+				require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+					Name: "TestEvent",
+				}))
+
+				select {
+				case event := <-eventCh:
+					t.Fatalf("Expected no telemetry event, but got: %v", event)
+				default:
+				}
+			})
+
+			t.Run("Allowed", func(t *testing.T) {
+				reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+					BaseURL:   server.URL,
+					AllowInCI: true,
+				})
+				t.Cleanup(func() {
+					require.NoError(t, reporter.Shutdown(ctx))
+				})
+
+				require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+					Name: "TestEvent",
+				}))
+
+				event := receiveEvent(t, eventCh)
+				assert.Equal(t, "TestEvent", event.Name)
+			})
+		})
+	}
+
+	t.Run("Explicitly False", func(t *testing.T) {
+		t.Setenv("CI", "false")
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: server.URL,
+		})
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		}))
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "TestEvent", event.Name)
+	})
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"sync"
+	"time"
+)
+
+// The value key used to flag events that observed the clock going backwards.
+const clockAnomalyValueKey = "clock_anomaly"
+
+// Clock is a source of the current time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// elapsed returns the duration between start and end. Times read from the
+// system clock carry a monotonic reading so wall clock steps are ignored, but
+// if the computed duration is still negative (eg. a clock without a monotonic
+// reading was stepped backwards) it is clamped to zero and ok is false.
+func elapsed(start, end time.Time) (d time.Duration, ok bool) {
+	d = end.Sub(start)
+	if d < 0 {
+		return 0, false
+	}
+
+	return d, true
+}
+
+// timestamper stamps events with the current time, guarding against the
+// clock going backwards.
+type timestamper struct {
+	clock Clock
+	// The precision timestamps are truncated to, or zero for full precision.
+	precision time.Duration
+	mu        sync.Mutex
+	// The timestamp of the most recently reported event.
+	last time.Time
+}
+
+func newTimestamper(clock Clock, precision time.Duration) *timestamper {
+	return &timestamper{
+		clock:     clock,
+		precision: precision,
+	}
+}
+
+// now returns the current time. If the clock has gone backwards since the
+// previous call, the previous timestamp is returned instead and ok is false.
+func (t *timestamper) now() (now time.Time, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now = t.clock.Now()
+	if !t.last.IsZero() {
+		if _, ok := elapsed(t.last, now); !ok {
+			return t.last, false
+		}
+	}
+
+	t.last = now
+
+	return now, true
+}
+
+// truncate truncates the timestamp to the configured precision.
+func (t *timestamper) truncate(timestamp time.Time) time.Time {
+	if t.precision <= 0 {
+		return timestamp
+	}
+
+	return timestamp.Truncate(t.precision)
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_ClockGoingBackwards(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Clock:   clock,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "BeforeStep",
+	})
+	first := receiveEvent(t, eventCh)
+
+	// Simulate an NTP step adjustment moving the wall clock backwards.
+	clock.Set(start.Add(-time.Hour))
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "AfterStep",
+	})
+	second := receiveEvent(t, eventCh)
+
+	assert.Equal(t, "BeforeStep", first.Name)
+	assert.NotContains(t, first.Values, "clock_anomaly")
+
+	assert.Equal(t, "AfterStep", second.Name)
+	assert.Equal(t, "true", second.Values["clock_anomaly"])
+
+	// The duration between the events should never be negative.
+	require.NotNil(t, first.Timestamp)
+	require.NotNil(t, second.Timestamp)
+	assert.GreaterOrEqual(t, second.Timestamp.Sub(*first.Timestamp), time.Duration(0))
+
+	// Once the clock moves forward again, events are no longer flagged.
+	clock.Set(start.Add(time.Second))
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "AfterRecovery",
+	})
+	third := receiveEvent(t, eventCh)
+
+	assert.NotContains(t, third.Values, "clock_anomaly")
+	assert.Equal(t, time.Second, third.Timestamp.Sub(*first.Timestamp))
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_TimestampPrecision(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 123456789, time.UTC)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:            server.URL,
+		Clock:              &fakeClock{now: now},
+		TimestampPrecision: time.Millisecond,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	// The sub-millisecond component is zeroed.
+	event := receiveEvent(t, eventCh)
+	require.NotNil(t, event.Timestamp)
+	assert.True(t, event.Timestamp.Equal(time.Date(2024, 1, 1, 0, 0, 0, 123000000, time.UTC)), event.Timestamp)
+	assert.Zero(t, event.Timestamp.Nanosecond()%int(time.Millisecond))
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_PresetTimestamp(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Clock:   &fakeClock{now: now},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// A historical event, being backfilled.
+	timestamp := now.Add(-time.Hour)
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name:      "Backfilled",
+		Timestamp: &timestamp,
+	})
+
+	event := receiveEvent(t, eventCh)
+	require.NotNil(t, event.Timestamp)
+	assert.True(t, event.Timestamp.Equal(timestamp), event.Timestamp)
+
+	// Events without a timestamp are still stamped with the current time.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "Current",
+	})
+
+	event = receiveEvent(t, eventCh)
+	require.NotNil(t, event.Timestamp)
+	assert.True(t, event.Timestamp.Equal(now), event.Timestamp)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *fakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
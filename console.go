@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// ConsoleFormat is the format events are written to the ConsoleWriter in.
+type ConsoleFormat string
+
+const (
+	// Each event is written as indented JSON (the default).
+	ConsoleFormatPretty ConsoleFormat = "pretty"
+	// Each event is written as JSON on a single line.
+	ConsoleFormatJSON ConsoleFormat = "json"
+)
+
+// consoleClient writes events to a writer instead of sending them.
+type consoleClient struct {
+	mu     sync.Mutex
+	w      io.Writer
+	format ConsoleFormat
+}
+
+func (c *consoleClient) ReportEvent(_ context.Context, event *v1alpha1.TelemetryEvent) error {
+	var eventJSON []byte
+	var err error
+	if c.format == ConsoleFormatJSON {
+		eventJSON, err = json.Marshal(event)
+	} else {
+		eventJSON, err = json.MarshalIndent(event, "", "  ")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, err := c.w.Write(append(eventJSON, '\n')); err != nil {
+		return fmt.Errorf("failed to write event: %w", err)
+	}
+
+	return nil
+}
+
+func (c *consoleClient) ReportEvents(ctx context.Context, events []*v1alpha1.TelemetryEvent) error {
+	for _, event := range events {
+		if err := c.ReportEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_ConsoleWriter(t *testing.T) {
+	tests := []struct {
+		name   string
+		format telemetry.ConsoleFormat
+		lines  int
+	}{
+		{name: "Pretty", format: telemetry.ConsoleFormatPretty},
+		{name: "JSON", format: telemetry.ConsoleFormatJSON, lines: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			// Create a new telemetry reporter, without a telemetry server.
+			conf := telemetry.Configuration{
+				Tags:          []string{"test-tag"},
+				ConsoleWriter: &buf,
+				ConsoleFormat: tt.format,
+			}
+
+			ctx := context.Background()
+			reporter, err := telemetry.NewReporterWithError(ctx, slog.Default(), conf)
+			require.NoError(t, err)
+
+			for _, name := range []string{"Event0", "Event1"} {
+				require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+					Name: name,
+				}))
+			}
+
+			require.NoError(t, reporter.Shutdown(ctx))
+
+			if tt.lines > 0 {
+				assert.Equal(t, tt.lines, strings.Count(buf.String(), "\n"))
+			} else {
+				assert.Contains(t, buf.String(), "\n  \"name\": \"Event0\"")
+			}
+
+			// The output should contain the final, enriched events.
+			dec := json.NewDecoder(&buf)
+			for _, name := range []string{"Event0", "Event1"} {
+				var event v1alpha1.TelemetryEvent
+				require.NoError(t, dec.Decode(&event))
+
+				assert.Equal(t, name, event.Name)
+				assert.Equal(t, v1alpha1.TelemetryEventKindInfo, event.Kind)
+				assert.Equal(t, []string{"test-tag"}, event.Tags)
+				assert.NotEmpty(t, event.SessionID)
+				assert.NotNil(t, event.Timestamp)
+			}
+
+			assert.False(t, dec.More())
+		})
+	}
+}
+
+func TestReporter_ConsoleWriterSynchronous(t *testing.T) {
+	var buf bytes.Buffer
+
+	// Create a new telemetry reporter, with a queue and batch that would
+	// otherwise hold up (or drop) events.
+	conf := telemetry.Configuration{
+		ConsoleWriter: &buf,
+		ConsoleFormat: telemetry.ConsoleFormatJSON,
+		QueueSize:     1,
+		BatchSize:     10,
+		BatchInterval: time.Hour,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for i := 0; i < 5; i++ {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+
+		// Each event is written before ReportEvent returns.
+		assert.Equal(t, i+1, strings.Count(buf.String(), "\n"))
+	}
+
+	stats := reporter.Stats()
+	assert.Equal(t, uint64(5), stats.Reported)
+	assert.Zero(t, stats.Dropped[telemetry.DropReasonQueueFull])
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"maps"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+type valuesContextKey struct{}
+
+// WithValues returns a copy of ctx carrying the given values. Events reported
+// with the returned context (eg. via ReportEventCtx) inherit these values,
+// unless the event explicitly sets the same key. Values added by nested calls
+// take precedence over those from enclosing contexts.
+func WithValues(ctx context.Context, values map[string]string) context.Context {
+	parent := valuesFromContext(ctx)
+
+	merged := make(map[string]string, len(parent)+len(values))
+	for k, v := range parent {
+		merged[k] = v
+	}
+	for k, v := range values {
+		merged[k] = v
+	}
+
+	return context.WithValue(ctx, valuesContextKey{}, merged)
+}
+
+// ValuesFromContext returns a copy of the values attached to ctx with
+// WithValues, or nil if there are none.
+func ValuesFromContext(ctx context.Context) map[string]string {
+	values := valuesFromContext(ctx)
+	if values == nil {
+		return nil
+	}
+
+	return maps.Clone(values)
+}
+
+func valuesFromContext(ctx context.Context) map[string]string {
+	values, _ := ctx.Value(valuesContextKey{}).(map[string]string)
+	return values
+}
+
+// mergeContextValues adds any values stored in ctx to the event, without
+// overriding values already set on the event.
+func mergeContextValues(ctx context.Context, event *v1alpha1.TelemetryEvent) {
+	for k, v := range valuesFromContext(ctx) {
+		if _, ok := event.Values[k]; !ok {
+			setValue(event, k, v)
+		}
+	}
+}
+
+type noReporterTagsContextKey struct{}
+
+// WithoutReporterTags returns a copy of ctx that opts events reported with it
+// (eg. via ReportEventCtx) out of the reporter's configured tags, so that they
+// carry only their own tags.
+func WithoutReporterTags(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noReporterTagsContextKey{}, true)
+}
+
+// reporterTagsDisabled returns whether ctx was created with WithoutReporterTags.
+func reporterTagsDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(noReporterTagsContextKey{}).(bool)
+	return disabled
+}
+
+type parentIDContextKey struct{}
+
+// WithParentID returns a copy of ctx carrying the ID of a parent operation (or
+// event). Events reported with the returned context (eg. via ReportEventCtx)
+// inherit it as their ParentID, unless they set their own.
+func WithParentID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, parentIDContextKey{}, id)
+}
+
+type correlationIDContextKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying a correlation ID. Events
+// reported with the returned context (eg. via ReportEventCtx) inherit it as
+// their CorrelationID, unless they set their own.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDContextKey{}, id)
+}
+
+// mergeContextIDs sets the event's parent and correlation IDs from ctx, if
+// the event doesn't set its own.
+func mergeContextIDs(ctx context.Context, event *v1alpha1.TelemetryEvent) {
+	if id, _ := ctx.Value(parentIDContextKey{}).(string); event.ParentID == "" {
+		event.ParentID = id
+	}
+
+	if id, _ := ctx.Value(correlationIDContextKey{}).(string); event.CorrelationID == "" {
+		event.CorrelationID = id
+	}
+}
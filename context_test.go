@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_WithValues(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	requestCtx := telemetry.WithValues(ctx, map[string]string{
+		"request_id": "abc123",
+		"user_tier":  "free",
+	})
+	requestCtx = telemetry.WithValues(requestCtx, map[string]string{
+		"user_tier": "pro",
+	})
+
+	event := &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+		Values: map[string]string{
+			"request_id": "explicit",
+		},
+	}
+
+	reporter.ReportEventCtx(requestCtx, event)
+
+	received := receiveEvent(t, eventCh)
+	assert.Equal(t, map[string]string{
+		"request_id": "explicit",
+		"user_tier":  "pro",
+	}, received.Values)
+
+	// The caller's event should not have been modified.
+	assert.Equal(t, map[string]string{"request_id": "explicit"}, event.Values)
+
+	require.NoError(t, reporter.ReportEventSync(requestCtx, &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	}))
+
+	received = receiveEvent(t, eventCh)
+	assert.Equal(t, map[string]string{
+		"request_id": "abc123",
+		"user_tier":  "pro",
+	}, received.Values)
+
+	// The enclosing context should be unaffected by nested values.
+	require.NoError(t, reporter.ReportEventSync(telemetry.WithValues(ctx, map[string]string{
+		"request_id": "def456",
+	}), &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	}))
+
+	received = receiveEvent(t, eventCh)
+	assert.Equal(t, map[string]string{"request_id": "def456"}, received.Values)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_CorrelationIDs(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// The fields are carried through to the server unchanged.
+	reporter.ReportEvent(v1alpha1.NewEvent("Operation").
+		ParentID("parent").
+		CorrelationID("correlation").
+		Build())
+
+	received := receiveEvent(t, eventCh)
+	assert.Equal(t, "parent", received.ParentID)
+	assert.Equal(t, "correlation", received.CorrelationID)
+
+	// Spawned events inherit the IDs from the context.
+	opCtx := telemetry.WithCorrelationID(ctx, "request-1")
+	opCtx = telemetry.WithParentID(opCtx, "operation-1")
+
+	require.NoError(t, reporter.ReportEventSync(opCtx, &v1alpha1.TelemetryEvent{
+		Name: "Spawned",
+	}))
+
+	received = receiveEvent(t, eventCh)
+	assert.Equal(t, "operation-1", received.ParentID)
+	assert.Equal(t, "request-1", received.CorrelationID)
+
+	// Unless the event sets its own.
+	reporter.ReportEventCtx(opCtx, &v1alpha1.TelemetryEvent{
+		Name:     "Explicit",
+		ParentID: "operation-2",
+	})
+
+	received = receiveEvent(t, eventCh)
+	assert.Equal(t, "operation-2", received.ParentID)
+	assert.Equal(t, "request-1", received.CorrelationID)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
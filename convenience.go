@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"errors"
+	"maps"
+	"strconv"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// The prefix of the value keys holding the message of each layer of a wrapped
+// error (eg. "error_0" is the outermost error).
+const errorChainValueKeyPrefix = "error_"
+
+// ReportInfo reports a named informational event with optional values.
+func (r *Reporter) ReportInfo(name string, values map[string]string) {
+	r.ReportEvent(&v1alpha1.TelemetryEvent{
+		Kind:   v1alpha1.TelemetryEventKindInfo,
+		Name:   name,
+		Values: values,
+	})
+}
+
+// ReportWarning reports a named warning event with a message and optional
+// values.
+func (r *Reporter) ReportWarning(name, message string, values map[string]string) {
+	r.ReportEvent(&v1alpha1.TelemetryEvent{
+		Kind:    v1alpha1.TelemetryEventKindWarning,
+		Name:    name,
+		Message: message,
+		Values:  values,
+	})
+}
+
+// ReportError reports a named error event with optional values. The error
+// is used as the event message, and the stack trace of the caller (up to
+// MaxStackDepth frames) is attached to the event. If the error wraps others,
+// the (redacted) message of each error in the chain is added to the values,
+// outermost first, under the keys "error_0", "error_1", and so on.
+func (r *Reporter) ReportError(name string, err error, values map[string]string) {
+	event := &v1alpha1.TelemetryEvent{
+		Kind:       v1alpha1.TelemetryEventKindError,
+		Name:       name,
+		Values:     values,
+		StackTrace: limitStackDepth(stackTrace(3, r.stackDepth), r.stackDepth),
+	}
+	if err != nil {
+		event.Message = err.Error()
+
+		if chain := errorChain(err); len(chain) > 1 {
+			event.Values = maps.Clone(values)
+			if event.Values == nil {
+				event.Values = make(map[string]string, len(chain))
+			}
+
+			for i, layer := range chain {
+				key := errorChainValueKeyPrefix + strconv.Itoa(i)
+				if _, ok := event.Values[key]; !ok {
+					event.Values[key] = r.redact(layer.Error())
+				}
+			}
+		}
+	}
+
+	r.ReportEvent(event)
+}
+
+// errorChain returns every error in the tree of err, depth first (as searched
+// by errors.Is), starting with err itself.
+func errorChain(err error) []error {
+	var chain []error
+	for err != nil {
+		chain = append(chain, err)
+
+		switch wrapped := err.(type) {
+		case interface{ Unwrap() []error }:
+			for _, e := range wrapped.Unwrap() {
+				chain = append(chain, errorChain(e)...)
+			}
+			return chain
+		default:
+			err = errors.Unwrap(err)
+		}
+	}
+
+	return chain
+}
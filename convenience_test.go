@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_ReportInfo(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportInfo("Started", map[string]string{"version": "v1.0.0"})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindInfo, event.Kind)
+	assert.Equal(t, "Started", event.Name)
+	assert.Equal(t, "v1.0.0", event.Values["version"])
+
+	reporter.ReportWarning("DiskSpace", "Disk almost full", nil)
+
+	event = receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindWarning, event.Kind)
+	assert.Equal(t, "DiskSpace", event.Name)
+	assert.Equal(t, "Disk almost full", event.Message)
+
+	reporter.ReportError("RequestFailed", errors.New("connection reset"), nil)
+
+	event = receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindError, event.Kind)
+	assert.Equal(t, "RequestFailed", event.Name)
+	assert.Equal(t, "connection reset", event.Message)
+	require.NotEmpty(t, event.StackTrace)
+	assert.Equal(t, "github.com/dpeckett/telemetry_test.TestReporter_ReportInfo", event.StackTrace[0].Function)
+
+	// Events without a kind default to info.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "TestEvent"})
+
+	event = receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindInfo, event.Kind)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_ReportErrorChain(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	t.Run("Wrapped", func(t *testing.T) {
+		cause := errors.New("connection reset")
+		err := fmt.Errorf("failed to upload: %w", fmt.Errorf("failed to write chunk: %w", cause))
+
+		values := map[string]string{"bucket": "backups"}
+		reporter.ReportError("UploadFailed", err, values)
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "failed to upload: failed to write chunk: connection reset", event.Message)
+		assert.Equal(t, map[string]string{
+			"bucket":  "backups",
+			"error_0": "failed to upload: failed to write chunk: connection reset",
+			"error_1": "failed to write chunk: connection reset",
+			"error_2": "connection reset",
+		}, event.Values)
+
+		// The caller's values are left untouched.
+		assert.Equal(t, map[string]string{"bucket": "backups"}, values)
+	})
+
+	t.Run("Joined", func(t *testing.T) {
+		err := fmt.Errorf("cleanup failed: %w", errors.Join(errors.New("remove a"), errors.New("remove b")))
+
+		reporter.ReportError("CleanupFailed", err, nil)
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "cleanup failed: remove a\nremove b", event.Values["error_0"])
+		assert.Equal(t, "remove a\nremove b", event.Values["error_1"])
+		assert.Equal(t, "remove a", event.Values["error_2"])
+		assert.Equal(t, "remove b", event.Values["error_3"])
+	})
+
+	t.Run("Unwrapped", func(t *testing.T) {
+		reporter.ReportError("RequestFailed", errors.New("connection reset"), nil)
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "connection reset", event.Message)
+		assert.Empty(t, event.Values)
+	})
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
@@ -0,0 +1,141 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+const (
+	// The default interval at which aggregated counters are flushed.
+	defaultCounterFlushInterval = time.Minute
+	// The value key holding the total of an aggregated counter.
+	countValueKey = "count"
+)
+
+type counter struct {
+	name  string
+	tags  []string
+	count int64
+}
+
+// counters aggregates counter increments client-side, periodically flushing
+// them as a single event per counter.
+type counters struct {
+	interval  time.Duration
+	report    func(*v1alpha1.TelemetryEvent)
+	mu        sync.Mutex
+	pending   map[string]*counter
+	stopped   bool
+	startOnce sync.Once
+	stopOnce  sync.Once
+	stopCh    chan struct{}
+}
+
+func newCounters(interval time.Duration, report func(*v1alpha1.TelemetryEvent)) *counters {
+	if interval <= 0 {
+		interval = defaultCounterFlushInterval
+	}
+
+	return &counters{
+		interval: interval,
+		report:   report,
+		pending:  make(map[string]*counter),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// add increments the named counter, starting the flush loop if needed. Once
+// stopped, increments are ignored.
+func (c *counters) add(name string, by int, tags []string) {
+	tags = slices.Clone(tags)
+	slices.Sort(tags)
+	key := name + "\x00" + strings.Join(tags, "\x00")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+
+	c.startOnce.Do(func() {
+		go c.run()
+	})
+
+	ctr, ok := c.pending[key]
+	if !ok {
+		ctr = &counter{name: name, tags: tags}
+		c.pending[key] = ctr
+	}
+
+	ctr.count += int64(by)
+}
+
+func (c *counters) run() {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stopCh:
+			return
+		case <-ticker.C:
+			c.flush()
+		}
+	}
+}
+
+// flush reports every pending counter.
+func (c *counters) flush() {
+	c.mu.Lock()
+	pending := c.pending
+	c.pending = make(map[string]*counter)
+	c.mu.Unlock()
+
+	for _, ctr := range pending {
+		c.report(&v1alpha1.TelemetryEvent{
+			Kind: v1alpha1.TelemetryEventKindInfo,
+			Name: ctr.name,
+			Tags: ctr.tags,
+			Values: map[string]string{
+				countValueKey: strconv.FormatInt(ctr.count, 10),
+			},
+		})
+	}
+}
+
+// stop stops the flush loop and ignores further increments. Pending counters
+// are not flushed.
+func (c *counters) stop() {
+	c.mu.Lock()
+	c.stopped = true
+	c.mu.Unlock()
+
+	c.stopOnce.Do(func() {
+		close(c.stopCh)
+	})
+}
+
+// Increment adds to a counter that is aggregated client-side, and periodically
+// reported as a single event with the total in its "count" value. Counters
+// are keyed by their name and tags, and any pending counts are flushed on
+// Shutdown.
+func (r *Reporter) Increment(name string, by int, tags ...string) {
+	// Include the tags of child reporters, as counters are reported by the
+	// parent.
+	r.counters.add(name, by, mergeTags(tags, r.tags))
+}
@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Increment(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:              server.URL,
+		Tags:                 []string{"test-tag"},
+		CounterFlushInterval: 50 * time.Millisecond,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.Increment("feature_used", 1, "feature:a", "plan:pro")
+	reporter.Increment("feature_used", 2, "plan:pro", "feature:a")
+	reporter.Increment("feature_used", 3, "feature:a", "plan:pro")
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindInfo, event.Kind)
+	assert.Equal(t, "feature_used", event.Name)
+	assert.ElementsMatch(t, []string{"feature:a", "plan:pro", "test-tag"}, event.Tags)
+	assert.Equal(t, "6", event.Values["count"])
+
+	// The counter should have been reset after being flushed.
+	reporter.Increment("feature_used", 1, "feature:a", "plan:pro")
+
+	event = receiveEvent(t, eventCh)
+	assert.Equal(t, "1", event.Values["count"])
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_IncrementShutdown(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:              server.URL,
+		CounterFlushInterval: time.Hour,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	child := reporter.WithTags("subsystem:auth")
+
+	for i := 0; i < 5; i++ {
+		child.Increment("login", 1)
+	}
+
+	// Pending counters are flushed on shutdown.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "login", event.Name)
+	assert.Equal(t, []string{"subsystem:auth"}, event.Tags)
+	assert.Equal(t, "5", event.Values["count"])
+
+	// Increments after shutdown are ignored.
+	child.Increment("login", 1)
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no further telemetry events, but got: %v", event)
+	default:
+	}
+}
@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import "github.com/dpeckett/telemetry/v1alpha1"
+
+// The value key of the reason the telemetry server gave for rejecting an event.
+const rejectedReasonValueKey = "telemetry_rejected_reason"
+
+// DropReason describes why an event was dropped rather than sent.
+type DropReason string
+
+const (
+	// Telemetry is disabled (eg. DO_NOT_TRACK is set, or running in CI).
+	DropReasonDisabled DropReason = "disabled"
+	// The reporter is shutting down.
+	DropReasonShuttingDown DropReason = "shutting_down"
+	// The queue of telemetry reports is full.
+	DropReasonQueueFull DropReason = "queue_full"
+	// The event was not selected by sampling.
+	DropReasonSampled DropReason = "sampled"
+	// The event was reported during the warmup period.
+	DropReasonSuppressed DropReason = "suppressed"
+	// The event exceeded the maximum payload size, even after truncation.
+	DropReasonTooLarge DropReason = "too_large"
+	// The event was older than the maximum event age by the time it was sent.
+	DropReasonExpired DropReason = "expired"
+	// A middleware blocked the event.
+	DropReasonBlocked DropReason = "blocked"
+	// The event was invalid (eg. nil).
+	DropReasonInvalid DropReason = "invalid"
+	// The event name was not allowed, or was blocked.
+	DropReasonFiltered DropReason = "filtered"
+	// The telemetry server rejected the event (and it was not retried). The
+	// reason given by the server is added to the event's values, under the
+	// "telemetry_rejected_reason" key.
+	DropReasonRejected DropReason = "rejected"
+	// Reporting was paused.
+	DropReasonPaused DropReason = "paused"
+)
+
+// Every drop reason.
+var dropReasons = []DropReason{
+	DropReasonDisabled,
+	DropReasonShuttingDown,
+	DropReasonQueueFull,
+	DropReasonSampled,
+	DropReasonSuppressed,
+	DropReasonTooLarge,
+	DropReasonExpired,
+	DropReasonBlocked,
+	DropReasonInvalid,
+	DropReasonFiltered,
+	DropReasonRejected,
+	DropReasonPaused,
+}
+
+// dropped records that an event was dropped, notifying the OnDrop callback if
+// configured.
+func (r *Reporter) dropped(event *v1alpha1.TelemetryEvent, reason DropReason) {
+	r.stats.dropped[reason].Add(1)
+
+	if reason == DropReasonQueueFull {
+		r.saturation.record(event)
+	}
+
+	if r.onDrop != nil {
+		r.onDrop(event, reason)
+	}
+}
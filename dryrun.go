@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// dryRunClient logs events instead of sending them.
+type dryRunClient struct {
+	logger  *slog.Logger
+	marshal func(*v1alpha1.TelemetryEvent) ([]byte, error)
+}
+
+func (c *dryRunClient) ReportEvent(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+	marshal := c.marshal
+	if marshal == nil {
+		marshal = func(event *v1alpha1.TelemetryEvent) ([]byte, error) {
+			return json.Marshal(event)
+		}
+	}
+
+	eventJSON, err := marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	c.logger.LogAttrs(ctx, slog.LevelInfo, "Dry run, not sending telemetry event",
+		slog.String("event", string(eventJSON)))
+
+	return nil
+}
+
+func (c *dryRunClient) ReportEvents(ctx context.Context, events []*v1alpha1.TelemetryEvent) error {
+	for _, event := range events {
+		if err := c.ReportEvent(ctx, event); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_DryRun(t *testing.T) {
+	// Start a mock telemetry server that should never be called.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Unexpected request in dry run mode")
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	handler := &recordingHandler{}
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Tags:    []string{"test-tag"},
+		DryRun:  true,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.New(handler), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+		Values: map[string]string{
+			"key1": "value1",
+		},
+	})
+
+	// Shutdown the reporter to wait for the event to be processed.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	var payloads []string
+	for _, record := range handler.Records() {
+		if record.Level != slog.LevelInfo {
+			continue
+		}
+
+		record.Attrs(func(attr slog.Attr) bool {
+			if attr.Key == "event" {
+				payloads = append(payloads, attr.Value.String())
+			}
+			return true
+		})
+	}
+	require.Len(t, payloads, 1)
+
+	var event v1alpha1.TelemetryEvent
+	require.NoError(t, json.Unmarshal([]byte(payloads[0]), &event))
+
+	assert.Equal(t, "TestEvent", event.Name)
+	assert.Equal(t, v1alpha1.TelemetryEventKindInfo, event.Kind)
+	assert.Equal(t, "value1", event.Values["key1"])
+	assert.Equal(t, []string{"test-tag"}, event.Tags)
+	assert.NotEmpty(t, event.SessionID)
+	assert.NotNil(t, event.Timestamp)
+	assert.Equal(t, v1alpha1.SchemaVersion, event.SchemaVersion)
+
+	assert.Equal(t, uint64(1), reporter.Stats().Reported)
+}
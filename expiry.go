@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"log/slog"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// expired returns whether the event is older than the maximum event age.
+func (r *Reporter) expired(event *v1alpha1.TelemetryEvent) bool {
+	if r.maxAge <= 0 || event.Timestamp == nil {
+		return false
+	}
+
+	age, _ := elapsed(*event.Timestamp, r.timestamps.clock.Now())
+	return age > r.maxAge
+}
+
+// dropExpired drops any expired events from the batch, returning the
+// remaining events.
+func (r *Reporter) dropExpired(batch []batchedEvent) []batchedEvent {
+	if r.maxAge <= 0 {
+		return batch
+	}
+
+	var remaining []batchedEvent
+	for _, e := range batch {
+		if r.expired(e.event) {
+			e.done()
+			r.dropped(e.event, DropReasonExpired)
+			continue
+		}
+
+		remaining = append(remaining, e)
+	}
+
+	if dropped := len(batch) - len(remaining); dropped > 0 {
+		r.logger.Debug("Events are too old, dropping events", slog.Int("count", dropped))
+	}
+
+	return remaining
+}
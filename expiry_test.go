@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_MaxEventAge(t *testing.T) {
+	t.Run("Batch", func(t *testing.T) {
+		// Start a mock telemetry server.
+		server, batchCh := mockBatchTelemetryServer(t, nil)
+		t.Cleanup(server.Close)
+
+		clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+		var dropped []string
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL:       server.URL,
+			BatchSize:     10,
+			BatchInterval: time.Hour,
+			MaxEventAge:   time.Minute,
+			Clock:         clock,
+			OnDrop: func(event *v1alpha1.TelemetryEvent, reason telemetry.DropReason) {
+				assert.Equal(t, telemetry.DropReasonExpired, reason)
+				dropped = append(dropped, event.Name)
+			},
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "StaleEvent"})
+
+		clock.Advance(2 * time.Minute)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "FreshEvent"})
+
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		batch := receiveBatch(t, batchCh)
+		assert.Equal(t, []string{"FreshEvent"}, eventNames(batch))
+		assert.Equal(t, []string{"StaleEvent"}, dropped)
+
+		stats := reporter.Stats()
+		assert.Equal(t, uint64(1), stats.Reported)
+		assert.Equal(t, uint64(1), stats.Dropped[telemetry.DropReasonExpired])
+	})
+
+	t.Run("Queue", func(t *testing.T) {
+		// Hold back sends until shutdown.
+		t.Cleanup(telemetry.SetRandInt64N(func(n int64) int64 {
+			return n - 1
+		}))
+
+		// Start a mock telemetry server.
+		server, eventCh := mockTelemetryServer(t)
+		t.Cleanup(server.Close)
+
+		clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL:       server.URL,
+			StartupJitter: time.Hour,
+			MaxEventAge:   time.Minute,
+			Clock:         clock,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "StaleEvent"})
+
+		clock.Advance(2 * time.Minute)
+
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		select {
+		case event := <-eventCh:
+			t.Fatalf("Expected no telemetry event, but got: %v", event)
+		default:
+		}
+
+		assert.Equal(t, uint64(1), reporter.Stats().Dropped[telemetry.DropReasonExpired])
+	})
+}
@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+)
+
+// CIEnvNames is exported for testing.
+var CIEnvNames = ciEnvNames
+
+// MaxConcurrentReports is exported for testing.
+const MaxConcurrentReports = maxConcurrentReports
+
+// SetRandInt64N replaces the source of randomness used for the startup
+// jitter, returning a function to restore the original.
+func SetRandInt64N(f func(n int64) int64) (restore func()) {
+	orig := randInt64N
+	randInt64N = f
+	return func() {
+		randInt64N = orig
+	}
+}
+
+// SetDoNotTrack overrides the DO_NOT_TRACK environment variable for reporters
+// created from now on, returning a function to restore the original.
+func SetDoNotTrack(doNotTrack bool) (restore func()) {
+	orig := lookupDoNotTrack
+	lookupDoNotTrack = func() bool {
+		return doNotTrack
+	}
+	return func() {
+		lookupDoNotTrack = orig
+	}
+}
+
+// SetNotifySignals replaces signal.Notify and signal.Stop, returning a
+// function to restore the originals.
+func SetNotifySignals(notify func(c chan<- os.Signal, sig ...os.Signal), stop func(c chan<- os.Signal)) (restore func()) {
+	origNotify, origStop := notifySignals, stopSignals
+	notifySignals, stopSignals = notify, stop
+	return func() {
+		notifySignals, stopSignals = origNotify, origStop
+	}
+}
+
+// SetDialContext replaces the function used to dial connections to the
+// telemetry server, returning a function to restore the original.
+func SetDialContext(f func(d *net.Dialer, ctx context.Context, network, address string) (net.Conn, error)) (restore func()) {
+	orig := dialContext
+	dialContext = f
+	return func() {
+		dialContext = orig
+	}
+}
+
+// OnCreateHTTPClient calls f whenever a default HTTP client is created,
+// returning a function to restore the original.
+func OnCreateHTTPClient(f func()) (restore func()) {
+	orig := createHTTPClient
+	createHTTPClient = func(logger *slog.Logger, conf Configuration) (*http.Client, error) {
+		f()
+		return orig(logger, conf)
+	}
+	return func() {
+		createHTTPClient = orig
+	}
+}
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// FailureReason describes why an event failed to send.
+type FailureReason string
+
+const (
+	// The telemetry server could not be reached (eg. the connection was
+	// refused or reset, or DNS resolution failed).
+	FailureReasonNetwork FailureReason = "network"
+	// The request timed out.
+	FailureReasonTimeout FailureReason = "timeout"
+	// The telemetry server responded with a 4xx status code.
+	FailureReasonClientError FailureReason = "client_error"
+	// The telemetry server responded with a 5xx status code.
+	FailureReasonServerError FailureReason = "server_error"
+	// The event could not be marshaled.
+	FailureReasonMarshal FailureReason = "marshal"
+	// Any other failure (eg. a response rejected by ValidateResponse).
+	FailureReasonOther FailureReason = "other"
+)
+
+// Every failure reason.
+var failureReasons = []FailureReason{
+	FailureReasonNetwork,
+	FailureReasonTimeout,
+	FailureReasonClientError,
+	FailureReasonServerError,
+	FailureReasonMarshal,
+	FailureReasonOther,
+}
+
+// classifyFailure returns the reason an event failed to send with err.
+func classifyFailure(err error) FailureReason {
+	if errors.Is(err, v1alpha1.ErrMarshal) {
+		return FailureReasonMarshal
+	}
+
+	var httpErr *v1alpha1.HTTPError
+	if errors.As(err, &httpErr) {
+		switch {
+		case httpErr.StatusCode >= http.StatusInternalServerError:
+			return FailureReasonServerError
+		case httpErr.StatusCode >= http.StatusBadRequest:
+			return FailureReasonClientError
+		default:
+			return FailureReasonOther
+		}
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return FailureReasonTimeout
+	}
+
+	if netErr != nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return FailureReasonNetwork
+	}
+
+	return FailureReasonOther
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// eventFilter filters events by name, against lists of exact names or glob
+// patterns (as understood by path.Match).
+type eventFilter struct {
+	allowed []string
+	blocked []string
+}
+
+// newEventFilter creates a filter from the allowed and blocked event name
+// patterns, which must already have been validated (see
+// validateEventPatterns).
+func newEventFilter(allowed, blocked []string) *eventFilter {
+	if len(allowed) == 0 {
+		allowed = nil
+	}
+
+	return &eventFilter{
+		allowed: allowed,
+		blocked: blocked,
+	}
+}
+
+// allow returns whether an event with the given name should be reported. The
+// block list takes precedence over the allow list, and an unset allow list
+// allows every event.
+func (f *eventFilter) allow(name string) bool {
+	if matchAny(f.blocked, name) {
+		return false
+	}
+
+	return f.allowed == nil || matchAny(f.allowed, name)
+}
+
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		// Patterns have already been validated.
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateEventPatterns checks that every event name pattern is a valid glob.
+func validateEventPatterns(conf Configuration) error {
+	for _, patterns := range [][]string{conf.AllowedEvents, conf.BlockedEvents} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid event name pattern %q: %w", pattern, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// valueKeys is an optional set of the value keys events are permitted to
+// carry. A nil set permits every key.
+type valueKeys map[string]struct{}
+
+func newValueKeys(keys []string) valueKeys {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	allowed := make(valueKeys, len(keys))
+	for _, key := range keys {
+		allowed[key] = struct{}{}
+	}
+
+	return allowed
+}
+
+// strip removes any values that are not permitted from the event, returning
+// the (sorted) keys that were removed.
+func (k valueKeys) strip(event *v1alpha1.TelemetryEvent) []string {
+	if k == nil {
+		return nil
+	}
+
+	var stripped []string
+	for key := range event.Values {
+		if _, ok := k[key]; !ok {
+			delete(event.Values, key)
+			stripped = append(stripped, key)
+		}
+	}
+
+	if len(event.Values) == 0 {
+		event.Values = nil
+	}
+
+	sort.Strings(stripped)
+
+	return stripped
+}
@@ -0,0 +1,195 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_EventFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		allowed  []string
+		blocked  []string
+		reported []string
+	}{
+		{
+			name:     "AllowList",
+			allowed:  []string{"Startup", "http.*"},
+			reported: []string{"Startup", "http.request", "http.error"},
+		},
+		{
+			name:     "BlockList",
+			blocked:  []string{"Debug*"},
+			reported: []string{"Startup", "http.request", "http.error"},
+		},
+		{
+			name:     "Precedence",
+			allowed:  []string{"http.*"},
+			blocked:  []string{"http.error"},
+			reported: []string{"http.request"},
+		},
+	}
+
+	names := []string{"Startup", "http.request", "http.error", "DebugDump"}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Start a mock telemetry server.
+			server, eventCh := mockTelemetryServer(t)
+			t.Cleanup(server.Close)
+
+			var mu sync.Mutex
+			var filtered []string
+
+			// Create a new telemetry reporter.
+			conf := telemetry.Configuration{
+				BaseURL:       server.URL,
+				AllowedEvents: tt.allowed,
+				BlockedEvents: tt.blocked,
+				OnDrop: func(event *v1alpha1.TelemetryEvent, reason telemetry.DropReason) {
+					mu.Lock()
+					defer mu.Unlock()
+
+					if reason == telemetry.DropReasonFiltered {
+						filtered = append(filtered, event.Name)
+					}
+				},
+			}
+
+			ctx := context.Background()
+			reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+			var reported []string
+			for _, name := range names {
+				require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+					Name: name,
+				}))
+
+				select {
+				case event := <-eventCh:
+					reported = append(reported, event.Name)
+				default:
+				}
+			}
+
+			assert.Equal(t, tt.reported, reported)
+
+			mu.Lock()
+			assert.Len(t, filtered, len(names)-len(tt.reported))
+			mu.Unlock()
+
+			// Shutdown the reporter to ensure graceful exit.
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+	}
+}
+
+func TestReporter_EventFilterInvalid(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := telemetry.NewReporterWithError(ctx, slog.Default(), telemetry.Configuration{
+		BaseURL:       "http://telemetry.invalid",
+		AllowedEvents: []string{"http.[*"},
+	})
+	require.Error(t, err)
+
+	_, err = telemetry.NewReporterWithError(ctx, slog.Default(), telemetry.Configuration{
+		BaseURL:       "http://telemetry.invalid",
+		BlockedEvents: []string{"Debug["},
+	})
+	require.Error(t, err)
+
+	t.Run("Multi", func(t *testing.T) {
+		// Start a mock telemetry server.
+		server, eventCh := mockTelemetryServer(t)
+		t.Cleanup(server.Close)
+
+		confs := []telemetry.Configuration{{
+			BaseURL:       server.URL,
+			BlockedEvents: []string{"[secret"},
+		}}
+
+		_, err := telemetry.NewMultiReporterWithError(ctx, slog.Default(), confs)
+		require.Error(t, err)
+
+		// Create a new telemetry reporter, it is disabled as the patterns
+		// are invalid.
+		reporter := telemetry.NewMultiReporter(ctx, slog.Default(), confs)
+
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "[secret",
+		}))
+
+		assert.Empty(t, eventCh)
+
+		// Shutdown the reporter to ensure graceful exit.
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+}
+
+func TestReporter_AllowedValueKeys(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:          server.URL,
+		AllowedValueKeys: []string{"user_id", "plan", "region"},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	ctx = telemetry.WithValues(ctx, map[string]string{
+		"region":   "eu-west-1",
+		"hostname": "example",
+	})
+
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+		Values: map[string]string{
+			"user_id": "1234",
+			"plan":    "pro",
+			"email":   "user@example.com",
+			"random":  "value",
+		},
+	}))
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, map[string]string{
+		"user_id": "1234",
+		"plan":    "pro",
+		"region":  "eu-west-1",
+	}, event.Values)
+
+	// Events with no allowed values carry none.
+	require.NoError(t, reporter.ReportEventSync(context.Background(), &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+		Values: map[string]string{
+			"email": "user@example.com",
+		},
+	}))
+
+	event = receiveEvent(t, eventCh)
+	assert.Empty(t, event.Values)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
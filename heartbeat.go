@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// HeartbeatEventName is the name given to the periodic heartbeat events
+// enabled by HeartbeatInterval.
+const HeartbeatEventName = "heartbeat"
+
+// The value key holding the number of seconds since the reporter was created.
+const uptimeValueKey = "uptime_seconds"
+
+// heartbeat periodically reports a heartbeat event, for as long as the
+// reporter is alive. A nil heartbeat does nothing.
+type heartbeat struct {
+	interval time.Duration
+	clock    Clock
+	started  time.Time
+	report   func(*v1alpha1.TelemetryEvent)
+	stopOnce sync.Once
+	stopCh   chan struct{}
+}
+
+func newHeartbeat(interval time.Duration, clock Clock, report func(*v1alpha1.TelemetryEvent)) *heartbeat {
+	if interval <= 0 {
+		return nil
+	}
+
+	return &heartbeat{
+		interval: interval,
+		clock:    clock,
+		started:  clock.Now(),
+		report:   report,
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// start starts reporting heartbeats in the background.
+func (h *heartbeat) start() {
+	if h == nil {
+		return
+	}
+
+	go h.run()
+}
+
+func (h *heartbeat) run() {
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-h.stopCh:
+			return
+		case <-ticker.C:
+			uptime, _ := elapsed(h.started, h.clock.Now())
+
+			h.report(&v1alpha1.TelemetryEvent{
+				Kind: v1alpha1.TelemetryEventKindInfo,
+				Name: HeartbeatEventName,
+				Values: map[string]string{
+					uptimeValueKey: strconv.FormatInt(int64(uptime/time.Second), 10),
+				},
+			})
+		}
+	}
+}
+
+// stop stops reporting heartbeats. It is safe to call more than once.
+func (h *heartbeat) stop() {
+	if h == nil {
+		return
+	}
+
+	h.stopOnce.Do(func() {
+		close(h.stopCh)
+	})
+}
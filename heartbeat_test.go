@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Heartbeat(t *testing.T) {
+	t.Run("Enabled", func(t *testing.T) {
+		server, heartbeats := mockHeartbeatServer(t)
+		t.Cleanup(server.Close)
+
+		clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL:           server.URL,
+			HeartbeatInterval: 10 * time.Millisecond,
+			Clock:             clock,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		require.Eventually(t, func() bool {
+			return len(heartbeats()) >= 2
+		}, time.Second, 5*time.Millisecond)
+
+		// Heartbeats carry the uptime of the reporter.
+		clock.Advance(90 * time.Second)
+
+		require.Eventually(t, func() bool {
+			events := heartbeats()
+			return events[len(events)-1].Values["uptime_seconds"] == "90"
+		}, time.Second, 5*time.Millisecond)
+
+		for _, event := range heartbeats() {
+			assert.Equal(t, v1alpha1.TelemetryEventKindInfo, event.Kind)
+			assert.Contains(t, event.Values, "uptime_seconds")
+		}
+
+		// Shutdown the reporter to ensure graceful exit.
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		// No more heartbeats are sent once the reporter is shut down.
+		count := len(heartbeats())
+		time.Sleep(50 * time.Millisecond)
+		assert.Len(t, heartbeats(), count)
+	})
+
+	t.Run("Do Not Track", func(t *testing.T) {
+		server, heartbeats := mockHeartbeatServer(t)
+		t.Cleanup(server.Close)
+
+		t.Cleanup(telemetry.SetDoNotTrack(true))
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL:           server.URL,
+			HeartbeatInterval: 10 * time.Millisecond,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		time.Sleep(50 * time.Millisecond)
+
+		assert.Empty(t, heartbeats())
+
+		// Heartbeats are never started, rather than being dropped.
+		assert.Zero(t, reporter.Stats().TotalDropped())
+
+		// Shutdown the reporter to ensure graceful exit.
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+}
+
+// mockHeartbeatServer starts a mock telemetry server that records every
+// heartbeat event it receives, without ever blocking.
+func mockHeartbeatServer(t *testing.T) (*httptest.Server, func() []*v1alpha1.TelemetryEvent) {
+	var mu sync.Mutex
+	var heartbeats []*v1alpha1.TelemetryEvent
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event v1alpha1.TelemetryEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if event.Name == telemetry.HeartbeatEventName {
+			mu.Lock()
+			heartbeats = append(heartbeats, &event)
+			mu.Unlock()
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	return server, func() []*v1alpha1.TelemetryEvent {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return append([]*v1alpha1.TelemetryEvent(nil), heartbeats...)
+	}
+}
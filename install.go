@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// The value key of the anonymous install ID.
+const installIDValueKey = "install_id"
+
+// installID is a stable, anonymous identifier for the installation, that
+// (unlike the session ID) persists across runs. It is loaded lazily, so that
+// nothing is written to disk unless an event is actually reported.
+type installID struct {
+	logger     *slog.Logger
+	path       string
+	generateID func() string
+	once       sync.Once
+	id         string
+}
+
+// newInstallID returns the install ID, either the given id, or one loaded
+// from (or created at) path. If neither is set, nil is returned (and a nil
+// install ID is always empty).
+func newInstallID(logger *slog.Logger, id, path string, generateID func() string) *installID {
+	if id == "" && path == "" {
+		return nil
+	}
+
+	// A supplied ID takes precedence, so there's nothing to load.
+	if id != "" {
+		path = ""
+	}
+
+	return &installID{
+		logger:     logger,
+		path:       path,
+		generateID: generateID,
+		id:         id,
+	}
+}
+
+// get returns the install ID, loading or creating it on first use. An empty
+// string is returned if it could not be loaded.
+func (i *installID) get() string {
+	if i == nil {
+		return ""
+	}
+
+	i.once.Do(func() {
+		if i.path == "" {
+			return
+		}
+
+		id, err := loadOrCreateInstallID(i.path, i.generateID)
+		if err != nil {
+			i.logger.Warn("Failed to load install ID", slog.Any("error", err))
+			return
+		}
+
+		i.id = id
+	})
+
+	return i.id
+}
+
+// loadOrCreateInstallID reads the install ID from path, creating the file
+// with a newly generated ID if it does not exist.
+func loadOrCreateInstallID(path string, generateID func() string) (string, error) {
+	id, err := readInstallID(path)
+	if !errors.Is(err, fs.ErrNotExist) {
+		return id, err
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create install ID directory: %w", err)
+	}
+
+	// The ID is written to a temporary file that is then linked into place,
+	// so the file is never seen partially written.
+	f, err := os.CreateTemp(dir, ".install-id-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create install ID file: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	id = generateID()
+
+	_, err = f.WriteString(id + "\n")
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to write install ID: %w", err)
+	}
+
+	// Another process may have created the file concurrently, in which case
+	// its ID is used.
+	if err := os.Link(f.Name(), path); errors.Is(err, fs.ErrExist) {
+		return readInstallID(path)
+	} else if err != nil {
+		return "", fmt.Errorf("failed to create install ID file: %w", err)
+	}
+
+	return id, nil
+}
+
+// readInstallID reads the install ID from path.
+func readInstallID(path string) (string, error) {
+	idBytes, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read install ID: %w", err)
+	}
+
+	id := strings.TrimSpace(string(idBytes))
+	if id == "" {
+		return "", fmt.Errorf("install ID file is empty: %s", path)
+	}
+
+	return id, nil
+}
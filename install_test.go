@@ -0,0 +1,133 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_InstallID(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "telemetry", "install-id")
+
+	reportInstallID := func(conf telemetry.Configuration) string {
+		conf.BaseURL = server.URL
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+
+		event := receiveEvent(t, eventCh)
+		return event.Values["install_id"]
+	}
+
+	var installID string
+
+	t.Run("First Run", func(t *testing.T) {
+		installID = reportInstallID(telemetry.Configuration{
+			InstallIDFile: path,
+		})
+		require.NotEmpty(t, installID)
+
+		// The generated ID is persisted.
+		idBytes, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Equal(t, installID, strings.TrimSpace(string(idBytes)))
+	})
+
+	t.Run("Subsequent Run", func(t *testing.T) {
+		// Unlike the session ID, the install ID is reused.
+		assert.Equal(t, installID, reportInstallID(telemetry.Configuration{
+			InstallIDFile: path,
+		}))
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "install-id")
+
+		// Create several reporters that each create the install ID at once.
+		const n = 8
+		for i := 0; i < n; i++ {
+			reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+				BaseURL:       server.URL,
+				InstallIDFile: path,
+			})
+			t.Cleanup(func() {
+				require.NoError(t, reporter.Shutdown(ctx))
+			})
+
+			reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+				Name: "TestEvent",
+			})
+		}
+
+		// Every reporter agrees on the install ID.
+		ids := make(map[string]struct{})
+		for i := 0; i < n; i++ {
+			ids[receiveEvent(t, eventCh).Values["install_id"]] = struct{}{}
+		}
+		require.Len(t, ids, 1)
+		assert.NotContains(t, ids, "")
+
+		// No temporary files are left behind.
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("Supplied", func(t *testing.T) {
+		assert.Equal(t, "my-install", reportInstallID(telemetry.Configuration{
+			InstallID: "my-install",
+		}))
+	})
+
+	t.Run("Do Not Track", func(t *testing.T) {
+		t.Setenv("DO_NOT_TRACK", "1")
+
+		path := filepath.Join(t.TempDir(), "install-id")
+
+		conf := telemetry.Configuration{
+			BaseURL:       server.URL,
+			InstallIDFile: path,
+		}
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		// The install ID file is never created.
+		_, err := os.Stat(path)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
@@ -11,21 +11,29 @@ package util
 
 import (
 	"crypto/rand"
-	"math/big"
+	"encoding/base64"
+	"io"
+	mathrand "math/rand/v2"
 )
 
+// The source of randomness used to generate IDs, overridden in tests.
+var randReader io.Reader = rand.Reader
+
+// GenerateID returns a random, URL-safe ID of n characters. Each character
+// carries 6 bits of entropy from crypto/rand. If the system's secure random
+// number generator fails, GenerateID falls back to a (non cryptographic)
+// randomly seeded generator rather than panicking.
 func GenerateID(n int) string {
-	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	if n <= 0 {
+		return ""
+	}
 
-	id := make([]byte, n)
-	for i := range id {
-		r, err := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
-		if err != nil {
-			panic(err)
+	b := make([]byte, base64.RawURLEncoding.DecodedLen(n)+1)
+	if _, err := io.ReadFull(randReader, b); err != nil {
+		for i := range b {
+			b[i] = byte(mathrand.Uint32())
 		}
-
-		id[i] = letters[r.Int64()]
 	}
 
-	return string(id)
+	return base64.RawURLEncoding.EncodeToString(b)[:n]
 }
@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package util
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateID(t *testing.T) {
+	charset := regexp.MustCompile(`^[A-Za-z0-9_-]*$`)
+
+	t.Run("Unique", func(t *testing.T) {
+		const n = 100000
+
+		seen := make(map[string]struct{}, n)
+		for i := 0; i < n; i++ {
+			id := GenerateID(16)
+
+			assert.Len(t, id, 16)
+			assert.Regexp(t, charset, id)
+
+			_, duplicate := seen[id]
+			assert.False(t, duplicate, "duplicate ID %q", id)
+			seen[id] = struct{}{}
+		}
+	})
+
+	t.Run("Length", func(t *testing.T) {
+		for _, n := range []int{0, 1, 7, 8, 21, 32, 64} {
+			id := GenerateID(n)
+			assert.Len(t, id, n)
+			assert.Regexp(t, charset, id)
+		}
+	})
+
+	t.Run("RNG Failure", func(t *testing.T) {
+		orig := randReader
+		randReader = failingReader{}
+		t.Cleanup(func() { randReader = orig })
+
+		assert.NotPanics(t, func() {
+			a, b := GenerateID(16), GenerateID(16)
+
+			assert.Len(t, a, 16)
+			assert.NotEqual(t, a, b)
+		})
+	})
+}
+
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("entropy exhausted")
+}
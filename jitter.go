@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// The source of randomness for the startup jitter, replaceable for testing.
+var randInt64N = rand.Int64N
+
+// startupDelay holds back background sends until a random delay after
+// startup has elapsed, so that a fleet of instances restarted together don't
+// all report at once.
+type startupDelay struct {
+	ready chan struct{}
+	once  sync.Once
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func newStartupDelay(jitter time.Duration) *startupDelay {
+	d := &startupDelay{
+		ready: make(chan struct{}),
+	}
+
+	if jitter <= 0 {
+		d.release()
+		return d
+	}
+
+	// The timer may fire before it has been assigned.
+	d.mu.Lock()
+	d.timer = time.AfterFunc(time.Duration(randInt64N(int64(jitter))), d.release)
+	d.mu.Unlock()
+
+	return d
+}
+
+// wait blocks until the startup delay has elapsed (or been released).
+func (d *startupDelay) wait(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-d.ready:
+		return nil
+	}
+}
+
+// release ends the startup delay early.
+func (d *startupDelay) release() {
+	d.once.Do(func() {
+		d.mu.Lock()
+		if d.timer != nil {
+			d.timer.Stop()
+		}
+		d.mu.Unlock()
+
+		close(d.ready)
+	})
+}
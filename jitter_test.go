@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_StartupJitter(t *testing.T) {
+	const jitter = 200 * time.Millisecond
+
+	// Always choose the maximum delay.
+	t.Cleanup(telemetry.SetRandInt64N(func(n int64) int64 {
+		return n - 1
+	}))
+
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		StartupJitter: jitter,
+	}
+
+	ctx := context.Background()
+	start := time.Now()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "FirstEvent"})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "FirstEvent", event.Name)
+	assert.GreaterOrEqual(t, time.Since(start), jitter-time.Millisecond)
+
+	// Subsequent events should not be delayed.
+	start = time.Now()
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "SecondEvent"})
+
+	event = receiveEvent(t, eventCh)
+	assert.Equal(t, "SecondEvent", event.Name)
+	assert.Less(t, time.Since(start), jitter/2)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_StartupJitterShutdown(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		StartupJitter: time.Hour,
+	}
+
+	t.Run("Shutdown", func(t *testing.T) {
+		// Always choose the maximum delay.
+		t.Cleanup(telemetry.SetRandInt64N(func(n int64) int64 {
+			return n - 1
+		}))
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "TestEvent"})
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+		t.Cleanup(cancel)
+
+		// Shutdown should send the event immediately, rather than waiting.
+		require.NoError(t, reporter.Shutdown(shutdownCtx))
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "TestEvent", event.Name)
+	})
+
+	t.Run("Close", func(t *testing.T) {
+		// Always choose the maximum delay.
+		t.Cleanup(telemetry.SetRandInt64N(func(n int64) int64 {
+			return n - 1
+		}))
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "TestEvent"})
+
+		// Close should drop the event without waiting.
+		require.NoError(t, reporter.Close())
+
+		select {
+		case event := <-eventCh:
+			t.Fatalf("Expected no telemetry event, but got: %v", event)
+		default:
+		}
+
+		assert.Equal(t, uint64(1), reporter.Stats().Dropped[telemetry.DropReasonShuttingDown])
+	})
+}
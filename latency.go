@@ -0,0 +1,177 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// The upper bounds of the latency histogram buckets (the same as the default
+// Prometheus buckets), there is an implicit final bucket for anything slower.
+var latencyBuckets = []time.Duration{
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	25 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// LatencyBucket is a bucket of a latency histogram.
+type LatencyBucket struct {
+	// UpperBound is the inclusive upper bound of the bucket, the final bucket
+	// has an upper bound of math.MaxInt64.
+	UpperBound time.Duration
+	// Count is the cumulative number of reports that took at most UpperBound.
+	Count uint64
+}
+
+// LatencyStats is a histogram of how long reports to the telemetry server took.
+type LatencyStats struct {
+	// Count is the number of reports (events, or batches of events) recorded.
+	Count uint64
+	// Sum is the total duration of every report.
+	Sum time.Duration
+	// Max is the duration of the slowest report.
+	Max time.Duration
+	// Buckets are the cumulative histogram buckets, in increasing order of
+	// upper bound.
+	Buckets []LatencyBucket
+}
+
+// Mean returns the mean duration of a report, or zero if none were recorded.
+func (s LatencyStats) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+
+	return s.Sum / time.Duration(s.Count)
+}
+
+// Quantile estimates the q-quantile (0 <= q <= 1) of the report durations by
+// linear interpolation within the histogram buckets, or zero if none were
+// recorded.
+func (s LatencyStats) Quantile(q float64) time.Duration {
+	if s.Count == 0 || len(s.Buckets) == 0 {
+		return 0
+	}
+
+	q = min(max(q, 0), 1)
+	rank := q * float64(s.Count)
+
+	var lowerBound time.Duration
+	var lowerCount uint64
+	for _, bucket := range s.Buckets {
+		if float64(bucket.Count) >= rank && bucket.Count > lowerCount {
+			upperBound := min(bucket.UpperBound, s.Max)
+			if upperBound < lowerBound {
+				return upperBound
+			}
+
+			fraction := (rank - float64(lowerCount)) / float64(bucket.Count-lowerCount)
+			return lowerBound + time.Duration(fraction*float64(upperBound-lowerBound))
+		}
+
+		lowerBound, lowerCount = bucket.UpperBound, bucket.Count
+	}
+
+	return s.Max
+}
+
+type latencyHistogram struct {
+	count atomic.Uint64
+	sum   atomic.Int64
+	max   atomic.Int64
+	// Non-cumulative counts, with one extra bucket for anything slower than
+	// the last bound.
+	buckets []atomic.Uint64
+}
+
+func newLatencyHistogram() *latencyHistogram {
+	return &latencyHistogram{
+		buckets: make([]atomic.Uint64, len(latencyBuckets)+1),
+	}
+}
+
+// observe records the duration of a report.
+func (h *latencyHistogram) observe(d time.Duration) {
+	i := 0
+	for i < len(latencyBuckets) && d > latencyBuckets[i] {
+		i++
+	}
+	h.buckets[i].Add(1)
+
+	h.sum.Add(int64(d))
+	for {
+		cur := h.max.Load()
+		if int64(d) <= cur || h.max.CompareAndSwap(cur, int64(d)) {
+			break
+		}
+	}
+
+	// Incremented last, so a concurrent snapshot never sees more reports than
+	// are accounted for in the buckets.
+	h.count.Add(1)
+}
+
+func (h *latencyHistogram) snapshot() LatencyStats {
+	snapshot := LatencyStats{
+		Count:   h.count.Load(),
+		Sum:     time.Duration(h.sum.Load()),
+		Max:     time.Duration(h.max.Load()),
+		Buckets: make([]LatencyBucket, len(h.buckets)),
+	}
+
+	var cumulative uint64
+	for i := range h.buckets {
+		cumulative += h.buckets[i].Load()
+
+		upperBound := time.Duration(math.MaxInt64)
+		if i < len(latencyBuckets) {
+			upperBound = latencyBuckets[i]
+		}
+
+		snapshot.Buckets[i] = LatencyBucket{UpperBound: upperBound, Count: cumulative}
+	}
+
+	return snapshot
+}
+
+// reportEvent sends a single event to the telemetry server, recording how
+// long it took.
+func (r *Reporter) reportEvent(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+	start := time.Now()
+	defer func() {
+		r.stats.latency.observe(time.Since(start))
+	}()
+
+	return r.client.ReportEvent(ctx, event)
+}
+
+// reportEvents sends a batch of events to the telemetry server, recording how
+// long it took.
+func (r *Reporter) reportEvents(ctx context.Context, events []*v1alpha1.TelemetryEvent) error {
+	start := time.Now()
+	defer func() {
+		r.stats.latency.observe(time.Since(start))
+	}()
+
+	return r.client.ReportEvents(ctx, events)
+}
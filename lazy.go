@@ -0,0 +1,51 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// lazyClient defers creating a client (and its HTTP transport) until the
+// first event is sent, so that creating a reporter which never reports (eg.
+// because telemetry is disabled) is essentially free.
+type lazyClient struct {
+	newClient func() eventClient
+	once      sync.Once
+	client    eventClient
+}
+
+func newLazyClient(newClient func() eventClient) *lazyClient {
+	return &lazyClient{newClient: newClient}
+}
+
+// get returns the client, creating it exactly once on first use.
+func (c *lazyClient) get() eventClient {
+	c.once.Do(func() {
+		c.client = c.newClient()
+	})
+
+	return c.client
+}
+
+func (c *lazyClient) ReportEvent(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+	return c.get().ReportEvent(ctx, event)
+}
+
+func (c *lazyClient) ReportEvents(ctx context.Context, events []*v1alpha1.TelemetryEvent) error {
+	return c.get().ReportEvents(ctx, events)
+}
+
+func (c *lazyClient) Ping(ctx context.Context) error {
+	return ping(ctx, c.get())
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_LazyClient(t *testing.T) {
+	var created atomic.Int64
+	restore := telemetry.OnCreateHTTPClient(func() {
+		created.Add(1)
+	})
+	t.Cleanup(restore)
+
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// No transport is created until the first event is sent.
+	assert.Zero(t, created.Load())
+
+	const senders = 8
+
+	go func() {
+		for range eventCh {
+		}
+	}()
+
+	// Concurrent first sends create the transport exactly once.
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			assert.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+				Name: "TestEvent",
+			}))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(1), created.Load())
+
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_LazyClientDisabled(t *testing.T) {
+	var created atomic.Int64
+	restore := telemetry.OnCreateHTTPClient(func() {
+		created.Add(1)
+	})
+	t.Cleanup(restore)
+
+	enabled := false
+	conf := telemetry.Configuration{
+		BaseURL: "http://telemetry.invalid",
+		Enabled: &enabled,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	// Telemetry is disabled, so no transport is ever created.
+	assert.Zero(t, created.Load())
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// SendFunc sends an event.
+type SendFunc func(ctx context.Context, event *v1alpha1.TelemetryEvent) error
+
+// Middleware wraps the sending of each event, so that it can be inspected or
+// modified just before it is sent. Returning an error without calling next
+// drops the event.
+type Middleware func(next SendFunc) SendFunc
+
+// chain wraps send in the middleware, the first middleware is the outermost.
+func chain(middleware []Middleware, send SendFunc) SendFunc {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		send = middleware[i](send)
+	}
+
+	return send
+}
+
+// sendEvent sends an event through the middleware chain. If a middleware
+// dropped the event, sent is false.
+func (r *Reporter) sendEvent(ctx context.Context, event *v1alpha1.TelemetryEvent) (sent bool, err error) {
+	if len(r.middleware) == 0 {
+		return true, r.reportEvent(ctx, event)
+	}
+
+	err = chain(r.middleware, func(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+		sent = true
+		return r.reportEvent(ctx, event)
+	})(ctx, event)
+	if !sent {
+		r.logger.Debug("Blocked by middleware, dropping event", slog.Any("error", err))
+		r.dropped(event, DropReasonBlocked)
+	}
+
+	return sent, err
+}
+
+// applyMiddleware runs each event in the batch through the middleware chain,
+// where next adds the event to the batch. Events blocked by a middleware are
+// dropped, and the remaining events are returned.
+func (r *Reporter) applyMiddleware(ctx context.Context, batch []batchedEvent) []batchedEvent {
+	if len(r.middleware) == 0 {
+		return batch
+	}
+
+	var remaining []batchedEvent
+	for _, e := range batch {
+		var accepted bool
+		err := chain(r.middleware, func(_ context.Context, event *v1alpha1.TelemetryEvent) error {
+			accepted = true
+			e.event = event
+			return nil
+		})(ctx, e.event)
+		if !accepted {
+			r.logger.Debug("Blocked by middleware, dropping event", slog.Any("error", err))
+			e.done()
+			r.dropped(e.event, DropReasonBlocked)
+			continue
+		}
+
+		remaining = append(remaining, e)
+	}
+
+	return remaining
+}
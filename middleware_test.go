@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// renameMiddleware prefixes the name of every event.
+func renameMiddleware(next telemetry.SendFunc) telemetry.SendFunc {
+	return func(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+		event.Name = "myapp." + event.Name
+		return next(ctx, event)
+	}
+}
+
+// blockMiddleware blocks any (renamed) event with an internal name.
+func blockMiddleware(next telemetry.SendFunc) telemetry.SendFunc {
+	return func(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+		if strings.HasPrefix(event.Name, "myapp.internal") {
+			return errors.New("blocked")
+		}
+
+		return next(ctx, event)
+	}
+}
+
+func TestReporter_Middleware(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:    server.URL,
+		Middleware: []telemetry.Middleware{renameMiddleware, blockMiddleware},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "TestEvent"})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "myapp.TestEvent", event.Name)
+
+	// Blocked events are dropped, rather than treated as errors.
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{Name: "internalEvent"}))
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no telemetry event, but got: %v", event)
+	default:
+	}
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	stats := reporter.Stats()
+	assert.Equal(t, uint64(1), stats.Reported)
+	assert.Equal(t, uint64(1), stats.Dropped[telemetry.DropReasonBlocked])
+}
+
+func TestReporter_MiddlewareBatch(t *testing.T) {
+	// Start a mock telemetry server.
+	server, batchCh := mockBatchTelemetryServer(t, nil)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		BatchSize:     10,
+		BatchInterval: time.Hour,
+		Middleware:    []telemetry.Middleware{renameMiddleware, blockMiddleware},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for i := 0; i < 2; i++ {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: fmt.Sprintf("Event%d", i)})
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: fmt.Sprintf("internalEvent%d", i)})
+	}
+
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	batch := receiveBatch(t, batchCh)
+	assert.Equal(t, []string{"myapp.Event0", "myapp.Event1"}, eventNames(batch))
+
+	stats := reporter.Stats()
+	assert.Equal(t, uint64(2), stats.Reported)
+	assert.Equal(t, uint64(2), stats.Dropped[telemetry.DropReasonBlocked])
+}
@@ -0,0 +1,125 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// NewMultiReporter creates a telemetry reporter that sends every event to
+// each of the configured telemetry servers concurrently. A failure to send to
+// one server does not prevent delivery to the others. Events in a batch that
+// are rejected by one server are counted as failed, rather than retried.
+//
+// Options concerning the connection to each server (eg. BaseURL, TLS,
+// MaxRetries, and RequestHook) are taken from each configuration. All other
+// options (eg. Tags, sampling, and batching) are taken from the first
+// configuration. If no configurations are provided, telemetry is disabled.
+// If any configuration is invalid, the problem is logged and telemetry is
+// disabled. Use NewMultiReporterWithError to handle the error instead.
+func NewMultiReporter(ctx context.Context, logger *slog.Logger, confs []Configuration) *Reporter {
+	logger = loggerOrDiscard(logger)
+
+	r, err := NewMultiReporterWithError(ctx, logger, confs)
+	if err != nil {
+		logger.Warn("Invalid telemetry configuration, telemetry is disabled", slog.Any("error", err))
+
+		// The client is never used, as every event is dropped.
+		r = newReporter(ctx, logger, confs[0], &dryRunClient{logger: logger}, true)
+	}
+
+	return r
+}
+
+// NewMultiReporterWithError creates a telemetry reporter that sends every
+// event to each of the configured telemetry servers, returning an error if any
+// configuration is invalid (eg. BaseURL is missing or malformed).
+func NewMultiReporterWithError(ctx context.Context, logger *slog.Logger, confs []Configuration) (*Reporter, error) {
+	logger = loggerOrDiscard(logger)
+
+	if len(confs) == 0 {
+		enabled := false
+		return NewReporterWithError(ctx, logger, Configuration{Enabled: &enabled})
+	}
+
+	for i, conf := range confs {
+		if err := validateConfiguration(conf); err != nil {
+			return nil, fmt.Errorf("invalid configuration %d: %w", i, err)
+		}
+
+		local := conf.DryRun || conf.ConsoleWriter != nil
+		if !local && conf.Transport == nil && conf.BaseURL == "" {
+			return nil, fmt.Errorf("invalid configuration %d: no telemetry server configured", i)
+		}
+	}
+
+	clients := make(multiClient, len(confs))
+	for i, conf := range confs {
+		clients[i] = newLazyClient(func() eventClient {
+			return newClient(logger, conf)
+		})
+	}
+
+	return newReporter(ctx, logger, confs[0], clients, false), nil
+}
+
+// multiClient fans out events to multiple clients.
+type multiClient []eventClient
+
+func (c multiClient) ReportEvent(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+	return c.fanOut(func(client eventClient) error {
+		return client.ReportEvent(ctx, event)
+	})
+}
+
+func (c multiClient) ReportEvents(ctx context.Context, events []*v1alpha1.TelemetryEvent) error {
+	if len(c) == 1 {
+		return c[0].ReportEvents(ctx, events)
+	}
+
+	return c.fanOut(func(client eventClient) error {
+		err := client.ReportEvents(ctx, events)
+
+		// Retrying events rejected by one client would resend them to every
+		// client, including those that accepted them, so the rejection is
+		// reported as a failure instead.
+		var rejectedErr *v1alpha1.RejectedEventsError
+		if errors.As(err, &rejectedErr) {
+			return fmt.Errorf("failed to report events: %v", err)
+		}
+
+		return err
+	})
+}
+
+// fanOut calls send for every client concurrently, returning the combined
+// errors.
+func (c multiClient) fanOut(send func(eventClient) error) error {
+	errs := make([]error, len(c))
+
+	var wg sync.WaitGroup
+	for i, client := range c {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			errs[i] = send(client)
+		}()
+	}
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMultiReporter(t *testing.T) {
+	// Start two mock telemetry servers.
+	saasServer, saasEventCh := mockTelemetryServer(t)
+	t.Cleanup(saasServer.Close)
+
+	internalServer, internalEventCh := mockTelemetryServer(t)
+	t.Cleanup(internalServer.Close)
+
+	// Create a new telemetry reporter.
+	confs := []telemetry.Configuration{
+		{BaseURL: saasServer.URL, Tags: []string{"test-tag"}},
+		{BaseURL: internalServer.URL},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewMultiReporter(ctx, slog.Default(), confs)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	saasEvent := receiveEvent(t, saasEventCh)
+	internalEvent := receiveEvent(t, internalEventCh)
+
+	assert.Equal(t, "TestEvent", saasEvent.Name)
+	assert.Equal(t, []string{"test-tag"}, saasEvent.Tags)
+	assert.Equal(t, saasEvent, internalEvent)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestMultiReporter_PartialFailure(t *testing.T) {
+	// Start a mock telemetry server that never responds successfully.
+	release := make(chan struct{})
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(failingServer.Close)
+
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	confs := []telemetry.Configuration{
+		{BaseURL: failingServer.URL},
+		{BaseURL: server.URL},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewMultiReporter(ctx, slog.Default(), confs)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+	}()
+
+	// The healthy server should receive the event, even though the failing
+	// server hasn't responded yet.
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "TestEvent", event.Name)
+
+	close(release)
+
+	err := <-errCh
+	require.Error(t, err)
+
+	var httpErr *v1alpha1.HTTPError
+	require.True(t, errors.As(err, &httpErr))
+	assert.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestMultiReporter_Rejected(t *testing.T) {
+	// Start a mock telemetry server that rejects the first event of every
+	// batch, and another that accepts every event.
+	rejectingServer, rejectingBatchCh := mockBatchTelemetryServer(t, func(w http.ResponseWriter, events []*v1alpha1.TelemetryEvent) {
+		w.WriteHeader(http.StatusMultiStatus)
+		require.NoError(t, json.NewEncoder(w).Encode(v1alpha1.BatchResponse{
+			Rejected: []v1alpha1.RejectedEvent{{Index: 0, Reason: "invalid event"}},
+		}))
+	})
+	t.Cleanup(rejectingServer.Close)
+
+	server, batchCh := mockBatchTelemetryServer(t, nil)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	confs := []telemetry.Configuration{
+		{
+			BaseURL:       rejectingServer.URL,
+			BatchSize:     2,
+			BatchInterval: 50 * time.Millisecond,
+			MaxRetries:    1,
+		},
+		{BaseURL: server.URL},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewMultiReporter(ctx, slog.Default(), confs)
+
+	for _, name := range []string{"First", "Second"} {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: name,
+		})
+	}
+
+	assert.Equal(t, []string{"First", "Second"}, eventNames(receiveBatch(t, rejectingBatchCh)))
+	assert.Equal(t, []string{"First", "Second"}, eventNames(receiveBatch(t, batchCh)))
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	// The rejected event is not retried, as that would send a duplicate to
+	// the server that accepted it.
+	select {
+	case batch := <-batchCh:
+		t.Fatalf("Expected no further batches, but got: %v", eventNames(batch))
+	default:
+	}
+
+	stats := reporter.Stats()
+	assert.Equal(t, uint64(2), stats.Failed)
+	assert.Zero(t, stats.Dropped[telemetry.DropReasonRejected])
+}
+
+func TestMultiReporter_InvalidConfiguration(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name  string
+		confs []telemetry.Configuration
+	}{
+		{
+			name: "Blocked Events",
+			confs: []telemetry.Configuration{
+				{BaseURL: server.URL},
+				{BaseURL: server.URL, BlockedEvents: []string{"[secret"}},
+			},
+		},
+		{
+			name: "Missing Base URL",
+			confs: []telemetry.Configuration{
+				{BaseURL: server.URL},
+				{},
+			},
+		},
+		{
+			name: "Invalid Base URL",
+			confs: []telemetry.Configuration{
+				{BaseURL: server.URL},
+				{BaseURL: "ftp://telemetry.invalid"},
+			},
+		},
+		{
+			name: "Invalid Kind Route",
+			confs: []telemetry.Configuration{
+				{BaseURL: server.URL},
+				{BaseURL: server.URL, KindRoutes: map[v1alpha1.TelemetryEventKind]string{
+					v1alpha1.TelemetryEventKindError: "not a url",
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			_, err := telemetry.NewMultiReporterWithError(ctx, slog.Default(), tt.confs)
+			require.Error(t, err)
+
+			// Create a new telemetry reporter, it is disabled as one of the
+			// configurations is invalid.
+			reporter := telemetry.NewMultiReporter(ctx, slog.Default(), tt.confs)
+
+			require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+				Name: "[secret",
+			}))
+
+			// Shutdown the reporter to ensure graceful exit.
+			require.NoError(t, reporter.Shutdown(ctx))
+
+			assert.Empty(t, eventCh)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_SendInterval(t *testing.T) {
+	const sendInterval = 50 * time.Millisecond
+
+	var mu sync.Mutex
+	var arrivals []time.Time
+
+	// Start a mock telemetry server that records when each event arrives.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:      server.URL,
+		SendInterval: sendInterval,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for i := 0; i < 4; i++ {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+	}
+
+	// Shutdown the reporter to wait for all events to be sent.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, arrivals, 4)
+
+	sort.Slice(arrivals, func(i, j int) bool {
+		return arrivals[i].Before(arrivals[j])
+	})
+
+	// Allow a little slack for scheduling jitter.
+	for i := 1; i < len(arrivals); i++ {
+		assert.GreaterOrEqual(t, arrivals[i].Sub(arrivals[i-1]), sendInterval-5*time.Millisecond)
+	}
+}
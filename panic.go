@@ -0,0 +1,66 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// PanicEventName is the name of events reported by ReportPanic.
+const PanicEventName = "panic"
+
+// The number of additional frames captured by ReportPanic, to allow for the
+// deferred functions handling the panic, which are then removed.
+const panicHandlerDepth = 8
+
+// ReportPanic reports a recovered panic as an error event. The recovered
+// value is used as the event message, and the stack trace of the panicking
+// goroutine (up to MaxStackDepth frames) is attached to the event. It must be
+// called from a deferred function, eg.
+//
+//	defer func() {
+//		if r := recover(); r != nil {
+//			reporter.ReportPanic(r)
+//		}
+//	}()
+func (r *Reporter) ReportPanic(recovered any) {
+	stackTrace := panicStackTrace(stackTrace(3, r.stackDepth+panicHandlerDepth))
+
+	r.ReportEvent(&v1alpha1.TelemetryEvent{
+		Kind:       v1alpha1.TelemetryEventKindError,
+		Name:       PanicEventName,
+		Message:    fmt.Sprint(recovered),
+		StackTrace: limitStackDepth(stackTrace, r.stackDepth),
+	})
+}
+
+// Recover recovers from, and reports, a panic. It must be deferred directly,
+// eg. defer reporter.Recover(). The panic is not propagated further.
+func (r *Reporter) Recover() {
+	if recovered := recover(); recovered != nil {
+		r.ReportPanic(recovered)
+	}
+}
+
+// panicStackTrace removes the frames of the deferred functions handling a
+// panic, so that the stack trace starts at the site of the panic.
+func panicStackTrace(stackTrace []*v1alpha1.StackFrame) []*v1alpha1.StackFrame {
+	i := slices.IndexFunc(stackTrace, func(frame *v1alpha1.StackFrame) bool {
+		return frame.Function == "runtime.gopanic"
+	})
+	if i < 0 {
+		return stackTrace
+	}
+
+	return stackTrace[i+1:]
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Recover(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer reporter.Recover()
+
+		panicky()
+	}()
+	<-done
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindError, event.Kind)
+	assert.Equal(t, telemetry.PanicEventName, event.Name)
+	assert.Equal(t, "something went wrong", event.Message)
+
+	// The stack trace should start at the site of the panic.
+	require.NotEmpty(t, event.StackTrace)
+	assert.Equal(t, "github.com/dpeckett/telemetry_test.panicky", event.StackTrace[0].Function)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func panicky() {
+	panic("something went wrong")
+}
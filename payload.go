@@ -0,0 +1,213 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// The value key used to flag events that were truncated to fit the maximum
+// payload size.
+const truncatedValueKey = "telemetry_truncated"
+
+const (
+	// The value keys of the hashes of an event's name and message, set if
+	// they were truncated.
+	nameHashValueKey    = "telemetry_name_hash"
+	messageHashValueKey = "telemetry_message_hash"
+	// Appended to truncated names and messages.
+	truncationMarker = "…"
+)
+
+// truncateEvent truncates the event in place so that its marshaled size does
+// not exceed maxBytes. The stack trace is trimmed first (keeping the top most
+// frames), followed by the longest values. Returns false if the event still
+// does not fit.
+func truncateEvent(event *v1alpha1.TelemetryEvent, maxBytes int) (bool, error) {
+	size, err := marshaledSize(event)
+	if err != nil {
+		return false, err
+	}
+
+	if size <= maxBytes {
+		return true, nil
+	}
+
+	setValue(event, truncatedValueKey, "true")
+
+	// Trim the stack trace, starting with the outermost frames.
+	for len(event.StackTrace) > 0 {
+		if size, err = marshaledSize(event); err != nil {
+			return false, err
+		} else if size <= maxBytes {
+			return true, nil
+		}
+
+		event.StackTrace = event.StackTrace[:len(event.StackTrace)-1]
+	}
+
+	if len(event.StackTrace) == 0 {
+		event.StackTrace = nil
+	}
+
+	// Truncate the longest values first, ties are broken by key so that the
+	// result is deterministic.
+	keys := make([]string, 0, len(event.Values))
+	for k := range event.Values {
+		if k != truncatedValueKey {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(event.Values[keys[i]]) != len(event.Values[keys[j]]) {
+			return len(event.Values[keys[i]]) > len(event.Values[keys[j]])
+		}
+		return keys[i] < keys[j]
+	})
+
+	for _, k := range keys {
+		if size, err = marshaledSize(event); err != nil {
+			return false, err
+		} else if size <= maxBytes {
+			return true, nil
+		}
+
+		v := event.Values[k]
+		event.Values[k] = truncateString(v, len(v)-(size-maxBytes))
+	}
+
+	if size, err = marshaledSize(event); err != nil {
+		return false, err
+	}
+
+	return size <= maxBytes, nil
+}
+
+// limitValues limits the number of values on the event to maxValues, and the
+// length of each value to maxValueLength bytes (either limit is ignored if not
+// positive). Excess values are dropped in key order, so that the result is
+// deterministic. If anything was removed, the event is flagged as truncated.
+func limitValues(event *v1alpha1.TelemetryEvent, maxValues, maxValueLength int) {
+	truncated := false
+
+	if maxValues > 0 && len(event.Values) > maxValues {
+		keys := make([]string, 0, len(event.Values))
+		for k := range event.Values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys[maxValues:] {
+			delete(event.Values, k)
+		}
+
+		truncated = true
+	}
+
+	if maxValueLength > 0 {
+		for k, v := range event.Values {
+			if len(v) > maxValueLength {
+				event.Values[k] = truncateString(v, maxValueLength)
+				truncated = true
+			}
+		}
+	}
+
+	if truncated {
+		setValue(event, truncatedValueKey, "true")
+	}
+}
+
+// limitText limits the length of the event's name to maxNameLength bytes, and
+// its message to maxMessageLength bytes (either limit is ignored if not
+// positive). Truncated text ends with an ellipsis, and a hash of the original
+// is added to the values so that events can still be correlated. If anything
+// was truncated, the event is flagged as truncated.
+func limitText(event *v1alpha1.TelemetryEvent, maxNameLength, maxMessageLength int) {
+	truncated := false
+
+	if maxNameLength > 0 && len(event.Name) > maxNameLength {
+		setValue(event, nameHashValueKey, textHash(event.Name))
+		event.Name = truncateWithMarker(event.Name, maxNameLength)
+		truncated = true
+	}
+
+	if maxMessageLength > 0 && len(event.Message) > maxMessageLength {
+		setValue(event, messageHashValueKey, textHash(event.Message))
+		event.Message = truncateWithMarker(event.Message, maxMessageLength)
+		truncated = true
+	}
+
+	if truncated {
+		setValue(event, truncatedValueKey, "true")
+	}
+}
+
+// truncateWithMarker truncates s to at most n bytes, including a trailing
+// truncation marker (if there is room for one).
+func truncateWithMarker(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	if n <= len(truncationMarker) {
+		return truncateString(s, n)
+	}
+
+	return truncateString(s, n-len(truncationMarker)) + truncationMarker
+}
+
+// textHash returns a short (64-bit) hex encoded SHA-256 hash of s.
+func textHash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+func marshaledSize(event *v1alpha1.TelemetryEvent) (int, error) {
+	eventJSON, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(eventJSON), nil
+}
+
+// truncateString truncates s to at most n bytes, without splitting a UTF-8
+// encoded rune.
+func truncateString(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+
+	return s[:n]
+}
+
+// setValue sets a value on the event, initializing the values map if required.
+func setValue(event *v1alpha1.TelemetryEvent, key, value string) {
+	if event.Values == nil {
+		event.Values = make(map[string]string)
+	}
+
+	event.Values[key] = value
+}
@@ -0,0 +1,283 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_MaxPayloadBytes(t *testing.T) {
+	const maxPayloadBytes = 2048
+
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:         server.URL,
+		MaxPayloadBytes: maxPayloadBytes,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	var stackTrace []*v1alpha1.StackFrame
+	for i := 0; i < 100; i++ {
+		stackTrace = append(stackTrace, &v1alpha1.StackFrame{
+			File:     "/home/ci/go/src/github.com/dpeckett/telemetry/reporter.go",
+			Function: fmt.Sprintf("github.com/dpeckett/telemetry.frame%d", i),
+			Line:     int32(i),
+		})
+	}
+
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Kind:       v1alpha1.TelemetryEventKindError,
+		Name:       "TestEvent",
+		StackTrace: stackTrace,
+		Values: map[string]string{
+			"small": "value",
+			"large": strings.Repeat("x", 4096),
+		},
+	}))
+
+	event := receiveEvent(t, eventCh)
+
+	eventJSON, err := json.Marshal(event)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(eventJSON), maxPayloadBytes)
+
+	assert.Equal(t, "true", event.Values["telemetry_truncated"])
+	assert.Equal(t, "value", event.Values["small"])
+	assert.Less(t, len(event.Values["large"]), 4096)
+
+	// The stack trace was trimmed entirely as the large value is still too big.
+	assert.Empty(t, event.StackTrace)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_MaxPayloadBytesStackTrace(t *testing.T) {
+	const maxPayloadBytes = 2048
+
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:         server.URL,
+		MaxPayloadBytes: maxPayloadBytes,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	var stackTrace []*v1alpha1.StackFrame
+	for i := 0; i < 100; i++ {
+		stackTrace = append(stackTrace, &v1alpha1.StackFrame{
+			Function: fmt.Sprintf("github.com/dpeckett/telemetry.frame%d", i),
+			Line:     int32(i),
+		})
+	}
+
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Kind:       v1alpha1.TelemetryEventKindError,
+		Name:       "TestEvent",
+		StackTrace: stackTrace,
+	}))
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "true", event.Values["telemetry_truncated"])
+
+	// The innermost frames should be kept.
+	require.NotEmpty(t, event.StackTrace)
+	assert.Less(t, len(event.StackTrace), 100)
+	for i, frame := range event.StackTrace {
+		assert.Equal(t, stackTrace[i].Function, frame.Function)
+	}
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_MaxPayloadBytesDrop(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	var dropReasons []telemetry.DropReason
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:         server.URL,
+		MaxPayloadBytes: 1024,
+		OnDrop: func(event *v1alpha1.TelemetryEvent, reason telemetry.DropReason) {
+			dropReasons = append(dropReasons, reason)
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// The message is never truncated so the event can't be made to fit.
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Name:    "TestEvent",
+		Message: strings.Repeat("x", 2048),
+	}))
+
+	assert.Equal(t, []telemetry.DropReason{telemetry.DropReasonTooLarge}, dropReasons)
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no telemetry event, but got: %v", event)
+	default:
+	}
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_ValueLimits(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:        server.URL,
+		MaxValues:      3,
+		MaxValueLength: 8,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	t.Run("OverLimit", func(t *testing.T) {
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+			Values: map[string]string{
+				"e": "value",
+				"d": "value",
+				"c": "value",
+				"b": strings.Repeat("x", 100),
+				"a": "value",
+			},
+		}))
+
+		event := receiveEvent(t, eventCh)
+
+		// The first values in key order are kept, and long values truncated.
+		assert.Equal(t, map[string]string{
+			"a":                   "value",
+			"b":                   "xxxxxxxx",
+			"c":                   "value",
+			"telemetry_truncated": "true",
+		}, event.Values)
+	})
+
+	t.Run("WithinLimit", func(t *testing.T) {
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+			Values: map[string]string{
+				"a": "value",
+			},
+		}))
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, map[string]string{"a": "value"}, event.Values)
+	})
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_TextLimits(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:          server.URL,
+		MaxNameLength:    16,
+		MaxMessageLength: 32,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	hash := func(s string) string {
+		sum := sha256.Sum256([]byte(s))
+		return hex.EncodeToString(sum[:8])
+	}
+
+	t.Run("OverLimit", func(t *testing.T) {
+		name := "http.request." + strings.Repeat("segment.", 8)
+		message := strings.Repeat("failed to connect ", 8)
+
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name:    name,
+			Message: message,
+		}))
+
+		event := receiveEvent(t, eventCh)
+
+		assert.Equal(t, "http.request.…", event.Name)
+		assert.Len(t, event.Name, 16)
+		assert.Equal(t, "failed to connect failed to c…", event.Message)
+		assert.LessOrEqual(t, len(event.Message), 32)
+
+		assert.Equal(t, map[string]string{
+			"telemetry_name_hash":    hash(name),
+			"telemetry_message_hash": hash(message),
+			"telemetry_truncated":    "true",
+		}, event.Values)
+	})
+
+	t.Run("Multibyte", func(t *testing.T) {
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: strings.Repeat("é", 16),
+		}))
+
+		event := receiveEvent(t, eventCh)
+
+		// Runes are never split.
+		assert.Equal(t, strings.Repeat("é", 6)+"…", event.Name)
+	})
+
+	t.Run("WithinLimit", func(t *testing.T) {
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name:    "TestEvent",
+			Message: "Hello, world!",
+		}))
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "TestEvent", event.Name)
+		assert.Equal(t, "Hello, world!", event.Message)
+		assert.Empty(t, event.Values)
+	})
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+)
+
+// pinger is implemented by clients that can check the telemetry server is
+// reachable.
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks that the telemetry server is reachable and accepting requests
+// (eg. that its credentials are valid), which is useful for diagnosing
+// problems at startup. If events are sent to multiple servers, every server
+// is checked. ErrNoServer is returned if no telemetry server is configured,
+// ErrDisabled is returned without contacting the server if telemetry is
+// disabled, and nil is returned for local reporters (eg. with DryRun).
+func (r *Reporter) Ping(ctx context.Context) error {
+	if r.noServer {
+		return ErrNoServer
+	}
+
+	if r.doNotTrack || r.disabled.Load() || r.disabledInCI {
+		return ErrDisabled
+	}
+
+	return ping(ctx, r.client)
+}
+
+// ping pings the client, if it supports it.
+func ping(ctx context.Context, client eventClient) error {
+	if p, ok := client.(pinger); ok {
+		return p.Ping(ctx)
+	}
+
+	return nil
+}
+
+func (c multiClient) Ping(ctx context.Context) error {
+	return c.fanOut(func(client eventClient) error {
+		return ping(ctx, client)
+	})
+}
+
+func (r *kindRouter) Ping(ctx context.Context) error {
+	errs := []error{ping(ctx, r.fallback)}
+	for _, client := range r.routes {
+		errs = append(errs, ping(ctx, client))
+	}
+
+	return errors.Join(errs...)
+}
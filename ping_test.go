@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Ping(t *testing.T) {
+	// Start mock healthy and unhealthy telemetry servers.
+	healthyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/healthz", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(healthyServer.Close)
+
+	unhealthyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(unhealthyServer.Close)
+
+	ctx := context.Background()
+
+	t.Run("Healthy", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: healthyServer.URL,
+		})
+		t.Cleanup(func() { require.NoError(t, reporter.Close()) })
+
+		require.NoError(t, reporter.Ping(ctx))
+	})
+
+	t.Run("Unhealthy", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: unhealthyServer.URL,
+		})
+		t.Cleanup(func() { require.NoError(t, reporter.Close()) })
+
+		var httpErr *v1alpha1.HTTPError
+		require.ErrorAs(t, reporter.Ping(ctx), &httpErr)
+		assert.Equal(t, http.StatusInternalServerError, httpErr.StatusCode)
+	})
+
+	t.Run("Kind Routes", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: healthyServer.URL,
+			KindRoutes: map[v1alpha1.TelemetryEventKind]string{
+				v1alpha1.TelemetryEventKindError: unhealthyServer.URL,
+			},
+		})
+		t.Cleanup(func() { require.NoError(t, reporter.Close()) })
+
+		require.Error(t, reporter.Ping(ctx))
+	})
+
+	t.Run("No Server", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{})
+		t.Cleanup(func() { require.NoError(t, reporter.Close()) })
+
+		require.ErrorIs(t, reporter.Ping(ctx), telemetry.ErrNoServer)
+	})
+}
+
+func TestReporter_PingDisabled(t *testing.T) {
+	// Start a mock telemetry server that counts requests.
+	var hits atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	t.Run("Do Not Track", func(t *testing.T) {
+		t.Cleanup(telemetry.SetDoNotTrack(true))
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: server.URL,
+		})
+		t.Cleanup(func() { require.NoError(t, reporter.Close()) })
+
+		require.ErrorIs(t, reporter.Ping(ctx), telemetry.ErrDisabled)
+	})
+
+	t.Run("Not Enabled", func(t *testing.T) {
+		enabled := false
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: server.URL,
+			Enabled: &enabled,
+		})
+		t.Cleanup(func() { require.NoError(t, reporter.Close()) })
+
+		require.ErrorIs(t, reporter.Ping(ctx), telemetry.ErrDisabled)
+	})
+
+	t.Run("CI", func(t *testing.T) {
+		t.Setenv("CI", "true")
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: server.URL,
+		})
+		t.Cleanup(func() { require.NoError(t, reporter.Close()) })
+
+		require.ErrorIs(t, reporter.Ping(ctx), telemetry.ErrDisabled)
+	})
+
+	assert.Zero(t, hits.Load())
+}
@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import "time"
+
+// The default number of in-flight report slots reserved for high priority
+// events.
+const defaultReservedHighPrioritySlots = 4
+
+// newNormalSlots returns a semaphore limiting the number of accepted normal
+// priority reports, such that the reserved number of slots (out of capacity)
+// are always available to high priority events.
+func newNormalSlots(capacity, reserved int) chan struct{} {
+	if reserved == 0 {
+		reserved = defaultReservedHighPrioritySlots
+	}
+
+	// Normal priority events always get at least one slot.
+	reserved = min(max(reserved, 0), capacity-1)
+
+	return make(chan struct{}, capacity-reserved)
+}
+
+// tryGo queues f to be run as a report, if there is capacity for an event of
+// the given priority. If BlockTimeout is set, tryGo waits up to the timeout
+// for capacity to become available.
+func (r *Reporter) tryGo(highPriority bool, f func()) bool {
+	var timeout <-chan time.Time
+	if r.blockTimeout > 0 {
+		timer := time.NewTimer(r.blockTimeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	return r.queue.push(highPriority, f, timeout, r.reportsCtx.Done())
+}
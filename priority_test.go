@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Priority(t *testing.T) {
+	const reservedSlots = 2
+
+	var mu sync.Mutex
+	received := map[string]int{}
+
+	// Start a mock telemetry server that holds requests until released.
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event v1alpha1.TelemetryEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		<-release
+
+		mu.Lock()
+		received[event.Name]++
+		mu.Unlock()
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:                   server.URL,
+		ReservedHighPrioritySlots: reservedSlots,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// Flood the reporter with info events.
+	for i := 0; i < telemetry.MaxConcurrentReports*2; i++ {
+		reporter.ReportInfo("InfoEvent", nil)
+	}
+
+	// Error events, and explicitly high priority events, should still get
+	// through.
+	reporter.ReportError("ErrorEvent", nil, nil)
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name:     "HighPriorityEvent",
+		Priority: v1alpha1.TelemetryEventPriorityHigh,
+	})
+
+	// But only up to the number of reserved slots.
+	reporter.ReportError("ErrorEvent", nil, nil)
+
+	close(release)
+
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, map[string]int{
+		"InfoEvent":         telemetry.MaxConcurrentReports - reservedSlots,
+		"ErrorEvent":        1,
+		"HighPriorityEvent": 1,
+	}, received)
+
+	stats := reporter.Stats()
+	assert.Equal(t, uint64(telemetry.MaxConcurrentReports+reservedSlots+1), stats.Dropped[telemetry.DropReasonQueueFull])
+}
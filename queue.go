@@ -0,0 +1,182 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"sync"
+	"time"
+)
+
+// queue is a bounded queue of reports, serviced by a fixed pool of workers.
+// A report is accepted once it has been queued, and capacity is only released
+// once a worker has finished sending it.
+type queue struct {
+	// Admission control, the number of accepted (queued or in-flight) reports.
+	slots chan struct{}
+	// The number of accepted normal priority reports.
+	normalSlots chan struct{}
+	// Reports waiting for a worker, high priority reports are serviced first.
+	high   chan func()
+	normal chan func()
+	// Guards against reports being accepted once the queue is closed.
+	mu      sync.RWMutex
+	closed  bool
+	pending sync.WaitGroup
+	stop    chan struct{}
+	stopped sync.Once
+	workers sync.WaitGroup
+}
+
+// newQueue creates a queue serviced by the given number of workers, holding
+// up to size reports (in addition to those in-flight). The reserved number of
+// slots are only available to high priority reports.
+func newQueue(workers, size, reserved int) *queue {
+	capacity := workers + max(size, 0)
+
+	q := &queue{
+		slots:       make(chan struct{}, capacity),
+		normalSlots: newNormalSlots(capacity, reserved),
+		high:        make(chan func(), capacity),
+		normal:      make(chan func(), capacity),
+		stop:        make(chan struct{}),
+	}
+
+	q.workers.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+// push accepts f, to be run by a worker. If timeout is nil, push doesn't wait
+// for capacity to become available. Closing cancel aborts the wait.
+func (q *queue) push(highPriority bool, f func(), timeout <-chan time.Time, cancel <-chan struct{}) bool {
+	if !highPriority {
+		if !acquire(q.normalSlots, timeout, cancel) {
+			return false
+		}
+	}
+
+	release := func() {
+		<-q.slots
+		if !highPriority {
+			<-q.normalSlots
+		}
+	}
+
+	if !acquire(q.slots, timeout, cancel) {
+		if !highPriority {
+			<-q.normalSlots
+		}
+
+		return false
+	}
+
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	if q.closed {
+		release()
+		return false
+	}
+
+	q.pending.Add(1)
+
+	task := func() {
+		defer q.pending.Done()
+		defer release()
+
+		f()
+	}
+
+	// Never blocks, as the channels have room for every accepted report.
+	if highPriority {
+		q.high <- task
+	} else {
+		q.normal <- task
+	}
+
+	return true
+}
+
+// depth returns the number of reports waiting for a worker.
+func (q *queue) depth() int {
+	return len(q.high) + len(q.normal)
+}
+
+// close stops the queue from accepting new reports.
+func (q *queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.closed = true
+}
+
+// wait blocks until every accepted report has been run, it must only be
+// called once the queue is closed.
+func (q *queue) wait() {
+	q.pending.Wait()
+}
+
+// stopWorkers stops the pool of workers, it must only be called once every
+// accepted report has been run.
+func (q *queue) stopWorkers() {
+	q.stopped.Do(func() {
+		close(q.stop)
+	})
+
+	q.workers.Wait()
+}
+
+func (q *queue) work() {
+	defer q.workers.Done()
+
+	for {
+		// Prefer high priority reports.
+		select {
+		case task := <-q.high:
+			task()
+			continue
+		default:
+		}
+
+		select {
+		case task := <-q.high:
+			task()
+		case task := <-q.normal:
+			task()
+		case <-q.stop:
+			return
+		}
+	}
+}
+
+// acquire takes a slot from the semaphore. If timeout is nil, acquire doesn't
+// wait for a slot to become available.
+func acquire(sem chan struct{}, timeout <-chan time.Time, cancel <-chan struct{}) bool {
+	if timeout == nil {
+		select {
+		case sem <- struct{}{}:
+			return true
+		default:
+			return false
+		}
+	}
+
+	select {
+	case sem <- struct{}{}:
+		return true
+	case <-cancel:
+		return false
+	case <-timeout:
+		return false
+	}
+}
@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Queue(t *testing.T) {
+	const (
+		queueSize = 8
+		overflow  = 5
+	)
+
+	var inFlight, received atomic.Int64
+
+	// Start a mock telemetry server that holds requests until released.
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+
+		inFlight.Add(1)
+		<-release
+		received.Add(1)
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:                   server.URL,
+		QueueSize:                 queueSize,
+		ReservedHighPrioritySlots: -1,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for i := 0; i < telemetry.MaxConcurrentReports+queueSize+overflow; i++ {
+		reporter.ReportInfo("TestEvent", nil)
+	}
+
+	// Wait for every worker to be busy sending.
+	require.Eventually(t, func() bool {
+		return inFlight.Load() == telemetry.MaxConcurrentReports
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The remaining accepted events should be waiting in the queue, and the
+	// excess dropped.
+	stats := reporter.Stats()
+	assert.Equal(t, queueSize, stats.QueueDepth)
+	assert.Equal(t, uint64(overflow), stats.Dropped[telemetry.DropReasonQueueFull])
+
+	close(release)
+
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	// Every accepted event should have been sent.
+	assert.Equal(t, int64(telemetry.MaxConcurrentReports+queueSize), received.Load())
+
+	stats = reporter.Stats()
+	assert.Zero(t, stats.QueueDepth)
+	assert.Equal(t, uint64(telemetry.MaxConcurrentReports+queueSize), stats.Reported)
+}
@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"net/netip"
+	"regexp"
+)
+
+var (
+	bearerTokenPattern = regexp.MustCompile(`(?i)\b(bearer)\s+[A-Za-z0-9\-._~+/]+=*`)
+	emailPattern       = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// Candidate IP addresses, which are validated before being masked so that
+	// eg. timestamps are left alone.
+	ipv4Pattern = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+	ipv6Pattern = regexp.MustCompile(`[0-9A-Fa-f]{0,4}(?::[0-9A-Fa-f]{0,4}){2,7}`)
+)
+
+// DefaultMessageRedactor masks anything in an event message resembling a
+// bearer token, an email address, or an IP address.
+func DefaultMessageRedactor(message string) string {
+	message = bearerTokenPattern.ReplaceAllString(message, "$1 [REDACTED]")
+	message = emailPattern.ReplaceAllString(message, "[EMAIL]")
+	message = ipv4Pattern.ReplaceAllStringFunc(message, maskIP)
+	message = ipv6Pattern.ReplaceAllStringFunc(message, maskIP)
+
+	return message
+}
+
+func maskIP(s string) string {
+	if _, err := netip.ParseAddr(s); err != nil {
+		return s
+	}
+
+	return "[IP]"
+}
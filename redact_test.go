@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultMessageRedactor(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{
+			message: "failed to notify alice@example.com from 192.168.1.10",
+			want:    "failed to notify [EMAIL] from [IP]",
+		},
+		{
+			message: "dial tcp [2001:db8::1]:443: connection refused",
+			want:    "dial tcp [[IP]]:443: connection refused",
+		},
+		{
+			message: "unauthorized: Authorization: Bearer eyJhbGciOi.J9.x_y-z",
+			want:    "unauthorized: Authorization: Bearer [REDACTED]",
+		},
+		{
+			message: "request timed out at 12:30:45 after 3.5s",
+			want:    "request timed out at 12:30:45 after 3.5s",
+		},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, telemetry.DefaultMessageRedactor(tt.message))
+	}
+}
+
+func TestReporter_MessageRedactor(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	t.Run("Default", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: server.URL,
+		})
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+
+		reporter.ReportError("SendFailed", errors.New("failed to send to bob@example.com via 10.0.0.1"), map[string]string{
+			"recipient": "bob@example.com",
+		})
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "failed to send to [EMAIL] via [IP]", event.Message)
+
+		// Values are not redacted.
+		assert.Equal(t, "bob@example.com", event.Values["recipient"])
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL:         server.URL,
+			MessageRedactor: strings.ToUpper,
+		})
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name:    "TestEvent",
+			Message: "sent to bob@example.com",
+		})
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "SENT TO BOB@EXAMPLE.COM", event.Message)
+	})
+}
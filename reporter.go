@@ -13,15 +13,17 @@ import (
 	"context"
 	_ "embed"
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"os"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/dpeckett/telemetry/internal/util"
 	"github.com/dpeckett/telemetry/v1alpha1"
-	"golang.org/x/sync/errgroup"
 )
 
 const (
@@ -29,121 +31,1330 @@ const (
 	doNotTrackEnvName = "DO_NOT_TRACK"
 	// The maximum number of in-flight telemetry reports.
 	maxConcurrentReports = 16
+	// The default length of generated session IDs.
+	defaultIDLength = 16
 )
 
+// Whether the DO_NOT_TRACK environment variable is set, read once when each
+// reporter is created (replaceable for testing).
+var lookupDoNotTrack = func() bool {
+	return os.Getenv(doNotTrackEnvName) != ""
+}
+
+// ErrShuttingDown is returned when an event is reported after the reporter has
+// started shutting down.
+var ErrShuttingDown = errors.New("telemetry reporter is shutting down")
+
+// ErrNoServer is returned by Ping when no telemetry server is configured.
+var ErrNoServer = errors.New("no telemetry server configured")
+
+// ErrDisabled is returned by Ping when telemetry is disabled (eg. by
+// DO_NOT_TRACK, or when running in CI).
+var ErrDisabled = errors.New("telemetry is disabled")
+
 // Configuration is the telemetry reporter configuration.
 type Configuration struct {
-	// BaseURL is the telemetry server base URL.
+	// BaseURL is the telemetry server base URL. A local telemetry agent can be
+	// reached over a Unix domain socket with a URL of the form
+	// unix:///run/telemetry.sock, unless HTTPClient is set.
 	BaseURL string
+	// Transport is an optional alternative transport used to send events
+	// (eg. telemetrygrpc.Transport), rather than HTTP/JSON. If set, BaseURL
+	// and KindRoutes are ignored, as are the options concerning the HTTP
+	// connection (eg. TLS, MaxRetries, and RequestHook).
+	Transport Transport
+	// KindRoutes optionally maps an event kind to the base URL of the
+	// telemetry server its events are sent to (eg. to send errors to an
+	// incident system). Kinds without a route are sent to BaseURL. Every route
+	// shares the remaining connection options (eg. TLS and MaxRetries).
+	KindRoutes map[v1alpha1.TelemetryEventKind]string
 	// Tags is a list of optional tags to include in all telemetry reports.
 	Tags []string
+	// UserAgent is an optional product token identifying the application (eg.
+	// "myapp/1.2.3"), it is prepended to the default User-Agent header.
+	UserAgent string
+	// HealthCheckPath is the path of the telemetry server endpoint requested
+	// by Ping. Defaults to /healthz.
+	HealthCheckPath string
 	// HTTPClient is the optional HTTP client to use for telemetry reporting.
+	// If set, the TLS, ProxyURL, MaxIdleConns, IdleConnTimeout,
+	// ForceAttemptHTTP2, DialTimeout, and TLSHandshakeTimeout options are
+	// ignored and the client is used as is.
 	HTTPClient *http.Client
+	// TLS is the optional TLS configuration used to connect to the telemetry
+	// server. It is ignored if HTTPClient is set.
+	TLS *TLSConfiguration
+	// ProxyURL is the optional URL of a proxy to use for telemetry reporting,
+	// overriding any proxy configured through the environment. It is ignored
+	// if HTTPClient is set.
+	ProxyURL string
+	// MaxIdleConns is the maximum number of idle connections to the telemetry
+	// server kept open for reuse. Defaults to 100. It is ignored if HTTPClient
+	// is set.
+	MaxIdleConns int
+	// IdleConnTimeout is how long an idle connection to the telemetry server
+	// is kept open for. Defaults to 90s. It is ignored if HTTPClient is set.
+	IdleConnTimeout time.Duration
+	// ForceAttemptHTTP2 controls whether HTTP/2 is attempted when connecting
+	// to the telemetry server. Defaults to true. It is ignored if HTTPClient
+	// is set.
+	ForceAttemptHTTP2 *bool
+	// DialTimeout is the maximum time spent establishing a connection to the
+	// telemetry server (including DNS resolution), independent of the overall
+	// request timeout. Defaults to 30s. It is ignored if HTTPClient is set.
+	DialTimeout time.Duration
+	// TLSHandshakeTimeout is the maximum time spent on the TLS handshake with
+	// the telemetry server. Defaults to 10s. It is ignored if HTTPClient is
+	// set.
+	TLSHandshakeTimeout time.Duration
+	// SampleRate is the fraction of events to report, in the range [0, 1].
+	// If unset, all events are reported.
+	SampleRate float64
+	// TagSampleRates is an optional map of tag to sample rate. When an event
+	// carries a matching tag, the tag's sample rate overrides SampleRate. If an
+	// event matches multiple tags, the highest rate is used.
+	TagSampleRates map[string]float64
+	// TargetEventsPerSecond is the optional maximum rate of events to report.
+	// As the observed event rate (averaged over roughly ten seconds) rises
+	// above the target, events are sampled with a decreasing probability.
+	// Error events are never dropped by this sampling.
+	TargetEventsPerSecond float64
+	// AllowedEvents is an optional list of event names (or glob patterns, eg.
+	// "http.*") to report. Other events are dropped. An invalid pattern is a
+	// configuration error.
+	AllowedEvents []string
+	// BlockedEvents is an optional list of event names (or glob patterns) to
+	// drop. It takes precedence over AllowedEvents.
+	BlockedEvents []string
+	// AllowedValueKeys is an optional list of the value keys events may carry
+	// (eg. to enforce a fixed analytics schema). Any other values, including
+	// those from the context, are removed before the event is sent, and a
+	// warning is logged. Values added by the reporter itself are unaffected.
+	AllowedValueKeys []string
+	// DisableIdempotencyKeys disables the Idempotency-Key header. By default
+	// each request is sent with a random key that is reused when the request
+	// is retried, so that the server can deduplicate events.
+	DisableIdempotencyKeys bool
+	// MaxErrorBodyBytes is the maximum number of bytes of an error response
+	// body captured (eg. for logging), the rest is discarded. Defaults to 4KiB.
+	MaxErrorBodyBytes int64
+	// MaxRetries is the maximum number of times an event that failed to send
+	// due to a transient error (or that was rejected as part of a batch) is
+	// retried. Defaults to no retries.
+	MaxRetries int
+	// Backoff is an optional strategy deciding the delay before an event that
+	// failed to send is retried. Defaults to an exponential backoff starting
+	// at 500ms, with jitter.
+	Backoff v1alpha1.BackoffStrategy
+	// BatchSize is the maximum number of events to send in a single request.
+	// If greater than one, events are buffered and sent in batches. Events
+	// are sent in the order they were reported within a batch, but ordering
+	// across batches (and of retried events) is best-effort.
+	BatchSize int
+	// MaxBatchBytes is the optional maximum total marshaled size of the
+	// events in a batch. A batch is sent early if adding another event would
+	// exceed the limit, whichever of BatchSize or MaxBatchBytes is reached
+	// first. It only applies if BatchSize is greater than one.
+	MaxBatchBytes int
+	// BatchInterval is the maximum duration an event is buffered for before
+	// its batch is sent. Defaults to 5 seconds.
+	BatchInterval time.Duration
+	// WarmupEvents is the number of events to suppress after the reporter is
+	// created, so that only steady-state telemetry is reported.
+	WarmupEvents int
+	// WarmupPeriod is the duration after the reporter is created during which
+	// events are suppressed. If both WarmupEvents and WarmupPeriod are set,
+	// an event is suppressed if either applies.
+	WarmupPeriod time.Duration
+	// ReportErrorsDuringWarmup allows error events to bypass warmup suppression.
+	ReportErrorsDuringWarmup bool
+	// MaxPayloadBytes is the optional maximum size of a marshaled event. Events
+	// exceeding the limit have their stack trace and values truncated, and are
+	// dropped if they still exceed the limit.
+	MaxPayloadBytes int
+	// SchemaVersion optionally overrides the schema version sent with each
+	// event, it defaults to v1alpha1.SchemaVersion. Events that set their own
+	// schema version are left unchanged.
+	SchemaVersion string
+	// MessageRedactor is an optional function used to redact sensitive
+	// details from event messages before they are sent. Defaults to
+	// DefaultMessageRedactor, which masks email addresses, IP addresses, and
+	// bearer tokens.
+	MessageRedactor func(string) string
+	// MaxValues is the optional maximum number of values an event can carry.
+	// Excess values are dropped (keeping the first in key order), and the
+	// event is marked with a "telemetry_truncated" value.
+	MaxValues int
+	// MaxValueLength is the optional maximum length of each value in bytes.
+	// Longer values are truncated, and the event is marked with a
+	// "telemetry_truncated" value.
+	MaxValueLength int
+	// MaxTags is the optional maximum number of tags an event can carry, after
+	// merging in (and deduplicating) the reporter's tags. Excess tags are
+	// dropped (keeping the event's own tags first), a warning is logged, and
+	// the event is marked with a "telemetry_truncated" value.
+	MaxTags int
+	// MaxNameLength is the optional maximum length of an event's name in
+	// bytes. Longer names are truncated (ending with "…"), the event is
+	// marked with a "telemetry_truncated" value, and a hash of the original
+	// name is added under the "telemetry_name_hash" value.
+	MaxNameLength int
+	// MaxMessageLength is the optional maximum length of an event's message
+	// in bytes. Longer messages are truncated as with MaxNameLength, and a
+	// hash of the original (redacted) message is added under the
+	// "telemetry_message_hash" value.
+	MaxMessageLength int
+	// MaxStackDepth is the maximum number of stack frames captured by
+	// ReportError and ReportPanic. The top frames are kept, and a frame with
+	// the function name "(truncated)" is appended if the stack was deeper.
+	// Defaults to 32.
+	MaxStackDepth int
+	// TrimPathPrefix is an optional list of path prefixes (eg. the module
+	// root on the build machine) to trim from stack frame file names. The
+	// GOROOT and GOPATH prefixes are always trimmed.
+	TrimPathPrefix []string
+	// MaxBreadcrumbs is the number of recently reported events remembered
+	// as breadcrumbs. When an error event is reported, the names and
+	// timestamps of the preceding events are attached to it as a JSON array
+	// under the "breadcrumbs" value. Defaults to 0, which disables
+	// breadcrumbs.
+	MaxBreadcrumbs int
+	// RuntimeStats attaches a snapshot of the Go runtime stats to events, to
+	// help diagnose performance related problems. The goroutine count, heap
+	// allocation, and number of completed GC cycles are added to the event's
+	// values (under the "runtime_goroutines", "runtime_heap_alloc_bytes", and
+	// "runtime_num_gc" keys).
+	RuntimeStats bool
+	// RuntimeStatsKinds is the kinds of events runtime stats are attached to.
+	// Defaults to error and warning events, as reading the stats briefly
+	// stops the world.
+	RuntimeStatsKinds []v1alpha1.TelemetryEventKind
+	// MaxEventAge is the optional maximum age of an event when it is sent.
+	// Older events (eg. that were queued during an outage) are dropped, as
+	// delivering them late would be misleading.
+	MaxEventAge time.Duration
+	// Middleware is an optional chain of functions wrapping the sending of
+	// each event, the first middleware is the outermost. For batches, the
+	// middleware runs for each event before the batch is sent, and next
+	// returns once the event has been added to the batch.
+	Middleware []Middleware
+	// ReservedHighPrioritySlots is the number of report slots (in-flight or
+	// queued) reserved for high priority events (error events, unless the
+	// event priority is set otherwise), so that they are still sent when the
+	// reporter is flooded with other events. Defaults to 4, set to a negative
+	// value to disable.
+	ReservedHighPrioritySlots int
+	// QueueSize is the number of events (or batches) that can be queued
+	// waiting to be sent, in addition to the in-flight reports. Events
+	// reported while the queue is full are dropped. Defaults to no queueing.
+	QueueSize int
+	// SaturationThreshold is the optional number of events that must be
+	// dropped (because the queue is full) within SaturationWindow for the
+	// reporter to be considered saturated. When the threshold is crossed a
+	// high priority warning event named SaturatedEventName is reported, at
+	// most once per window. The saturation event itself is not counted.
+	SaturationThreshold int
+	// SaturationWindow is the window over which dropped events are counted
+	// for SaturationThreshold. Defaults to one minute.
+	SaturationWindow time.Duration
+	// BlockTimeout is the optional maximum duration ReportEvent waits for
+	// room in the queue, rather than immediately dropping the event when the
+	// queue is full.
+	BlockTimeout time.Duration
+	// OnDrop is an optional function called whenever an event is dropped
+	// rather than sent. The event is nil if a nil event was reported.
+	OnDrop func(event *v1alpha1.TelemetryEvent, reason DropReason)
+	// Tee is an optional channel that receives a copy of every event accepted
+	// for sending (after it has been enriched), so it can also be consumed
+	// in-process (eg. to update a dashboard). Sends never block, if the
+	// channel is full the copy is discarded. The channel is never closed.
+	Tee chan<- *v1alpha1.TelemetryEvent
+	// SendInterval is the optional minimum delay between background sends.
+	// Pacing sends ensures telemetry doesn't compete with the application for
+	// network and CPU under load.
+	SendInterval time.Duration
+	// HeartbeatInterval is the optional interval at which a heartbeat event
+	// (named HeartbeatEventName, with the reporter's uptime in its
+	// "uptime_seconds" value) is reported, for as long as the reporter is
+	// alive. Heartbeats stop on Shutdown or Close, and are never started if
+	// telemetry is disabled by DO_NOT_TRACK.
+	HeartbeatInterval time.Duration
+	// CounterFlushInterval is the interval at which counters aggregated with
+	// Increment are reported. Defaults to one minute.
+	CounterFlushInterval time.Duration
+	// StartupJitter is the optional maximum delay before the first
+	// background send. The actual delay is chosen at random, so that a fleet
+	// of instances restarted at the same time don't all report at once.
+	StartupJitter time.Duration
+	// RequestHook is an optional function called with each outgoing request
+	// just before it is sent. It may modify the request, or abort the send by
+	// returning an error.
+	RequestHook func(*http.Request) error
+	// RequestSigner is an optional function called with each outgoing request
+	// and its body just before it is sent, to authenticate the request (eg.
+	// v1alpha1.HMACSigner). If set, streamed batches are buffered in memory
+	// so they can be signed.
+	RequestSigner func(req *http.Request, body []byte) error
+	// StreamBatches streams batches to the telemetry server as
+	// newline-delimited JSON, keeping memory usage bounded regardless of the
+	// batch size. It only applies if BatchSize is greater than one, and
+	// requires a JSON ContentType.
+	StreamBatches bool
+	// Marshaler is an optional function used to encode each event. Defaults
+	// to json.Marshal.
+	Marshaler func(*v1alpha1.TelemetryEvent) ([]byte, error)
+	// BatchMarshaler is an optional function used to encode each batch of
+	// events. Defaults to a JSON array of the events encoded by Marshaler. It
+	// is required if batching with a ContentType other than JSON.
+	BatchMarshaler func([]*v1alpha1.TelemetryEvent) ([]byte, error)
+	// ContentType is the Content-Type header sent with events encoded by
+	// Marshaler (eg. "application/msgpack"). Defaults to application/json.
+	ContentType string
+	// ValidateResponse is an optional function called with each successful
+	// response from the telemetry server. Returning an error marks the
+	// request as failed.
+	ValidateResponse func(*http.Response) error
+	// ValidateBatchResponse is an optional function called with each
+	// successful response to a batch of events, it is used instead of
+	// ValidateResponse for batches. Individual events can be marked as failed
+	// (and retried) by returning a *v1alpha1.RejectedEventsError. If unset,
+	// 207 Multi-Status responses are parsed with v1alpha1.ParseBatchResponse.
+	// Rejected events that are not retried are dropped with
+	// DropReasonRejected.
+	ValidateBatchResponse func(*http.Response, []*v1alpha1.TelemetryEvent) error
+	// Enabled is whether telemetry reporting is initially enabled, defaults to
+	// true. It can be toggled at runtime (eg. in response to user consent)
+	// with Reporter.Enable and Reporter.Disable. DO_NOT_TRACK always takes
+	// precedence.
+	Enabled *bool
+	// DryRun logs the final JSON of each event at info level, rather than
+	// sending it to the telemetry server. Events are otherwise processed as
+	// normal, and are counted as reported.
+	DryRun bool
+	// ConsoleWriter is an optional writer that the final JSON of each event is
+	// written to, rather than sending it to the telemetry server. Events are
+	// written synchronously, bypassing the queue and batching. It is intended
+	// for local development.
+	ConsoleWriter io.Writer
+	// ConsoleFormat is the format events are written to the ConsoleWriter
+	// in. Defaults to ConsoleFormatPretty.
+	ConsoleFormat ConsoleFormat
+	// AllowInCI enables telemetry reporting when running in a CI environment.
+	// By default, telemetry is disabled in CI to avoid skewing analytics.
+	AllowInCI bool
+	// FailureLogLevel is the level at which failures to send events are
+	// logged. Defaults to debug, so as to not spam the logs when offline.
+	FailureLogLevel slog.Leveler
+	// IDGenerator is an optional function used to generate session IDs (and
+	// install IDs). Defaults to a random URL-safe ID of IDLength characters.
+	IDGenerator func() string
+	// InstallID is an optional stable, anonymous identifier for the
+	// installation, attached to every event as the "install_id" value.
+	// Unlike the session ID, it is expected to persist across runs.
+	InstallID string
+	// InstallIDFile is the optional path of a file the install ID is
+	// persisted in, it is used if InstallID is unset. If the file doesn't
+	// exist, it is created with a newly generated ID when the first event is
+	// reported, so nothing is written while telemetry is disabled (eg. by
+	// DO_NOT_TRACK).
+	InstallIDFile string
+	// IDLength is the length of generated session IDs. Defaults to 16
+	// characters (96 bits of entropy).
+	IDLength int
+	// TimestampPrecision is the optional precision event timestamps are
+	// truncated to (eg. time.Millisecond), reducing the payload size and
+	// clock jitter noise. Defaults to full precision.
+	TimestampPrecision time.Duration
+	// Clock is the optional source of the current time, defaults to the
+	// system clock.
+	Clock Clock
 }
 
+// EventReporter reports telemetry events. It is implemented by *Reporter,
+// and allows call sites to be tested without a telemetry server (see the
+// telemetrytest package).
+type EventReporter interface {
+	// ReportEvent reports a telemetry event.
+	ReportEvent(event *v1alpha1.TelemetryEvent)
+	// ReportEventCtx reports a telemetry event, merging in any values attached
+	// to the context with WithValues.
+	ReportEventCtx(ctx context.Context, event *v1alpha1.TelemetryEvent)
+	// ReportEventSync reports a telemetry event and waits for it to be sent.
+	ReportEventSync(ctx context.Context, event *v1alpha1.TelemetryEvent) error
+}
+
+var _ EventReporter = (*Reporter)(nil)
+
+// Transport sends events to the telemetry server. Events are sent over
+// HTTP/JSON by default, an alternative transport (eg. gRPC, see the
+// telemetrygrpc package) can be supplied with Configuration.Transport.
+type Transport interface {
+	// ReportEvent sends a single event.
+	ReportEvent(ctx context.Context, event *v1alpha1.TelemetryEvent) error
+	// ReportEvents sends a batch of events. Individual events can be marked
+	// as failed by returning a *v1alpha1.RejectedEventsError.
+	ReportEvents(ctx context.Context, events []*v1alpha1.TelemetryEvent) error
+}
+
+// eventClient sends events to the telemetry server.
+type eventClient = Transport
+
 // Reporter is a telemetry reporter.
 type Reporter struct {
 	logger       *slog.Logger
-	client       *v1alpha1.TelemetryEventClient
-	sessionID    string
+	client       eventClient
+	session      *session
+	installID    *installID
+	doNotTrack   bool
+	noServer     bool
+	console      bool
+	disabledInCI bool
+	disabled     *atomic.Bool
+	paused       *atomic.Bool
+	failureLevel slog.Leveler
 	tags         []string
+	sampler      *sampler
+	adaptive     *adaptiveSampler
+	filter       *eventFilter
+	valueKeys    valueKeys
+	timestamps   *timestamper
+	warmup       *warmup
+	barrier      *barrier
+	batcher      *batcher
+	startup      *startupDelay
+	pacer        *pacer
+	counters     *counters
+	heartbeat    *heartbeat
+	saturation   *saturation
+	redact       func(string) string
+	schemaVer    string
+	maxPayload   int
+	maxValues    int
+	maxValueLen  int
+	maxTags      int
+	maxNameLen   int
+	maxMsgLen    int
+	stackDepth   int
+	maxAge       time.Duration
+	breadcrumbs  *breadcrumbs
+	runtimeStats *runtimeStats
+	paths        *pathTrimmer
+	teeCh        chan<- *v1alpha1.TelemetryEvent
+	onDrop       func(*v1alpha1.TelemetryEvent, DropReason)
+	middleware   []Middleware
+	stats        *stats
+	maxRetries   int
 	reportsCtx   context.Context
-	reports      *errgroup.Group
-	shuttingDown atomic.Bool
+	cancel       context.CancelFunc
+	queue        *queue
+	lifecycle    *lifecycle
+	blockTimeout time.Duration
+	shuttingDown *atomic.Bool
 }
 
-// NewReporter creates a new telemetry reporter.
+// NewReporter creates a new telemetry reporter. If the configuration is
+// invalid, the problem is logged and telemetry is disabled. Use
+// NewReporterWithError to handle the error instead. If logger is nil, log
+// output is discarded. The reporter is tied to the lifecycle of ctx, once it
+// is cancelled the reporter is closed (as if by Close) and subsequent events
+// are dropped.
 func NewReporter(ctx context.Context, logger *slog.Logger, conf Configuration) *Reporter {
+	logger = loggerOrDiscard(logger)
+
+	r, err := NewReporterWithError(ctx, logger, conf)
+	if err != nil {
+		logger.Warn("Invalid telemetry configuration, telemetry is disabled", slog.Any("error", err))
+
+		// The client is never used, as every event is dropped.
+		r = newReporter(ctx, logger, conf, &dryRunClient{logger: logger}, true)
+	}
+
+	return r
+}
+
+// NewReporterWithError creates a new telemetry reporter, returning an error if
+// the configuration is invalid (eg. BaseURL is malformed, or the TLS CA bundle
+// can't be read). If no BaseURL is configured, the reporter silently drops
+// every event.
+func NewReporterWithError(ctx context.Context, logger *slog.Logger, conf Configuration) (*Reporter, error) {
+	logger = loggerOrDiscard(logger)
+
+	if err := validateConfiguration(conf); err != nil {
+		return nil, err
+	}
+
+	local := conf.DryRun || conf.ConsoleWriter != nil
+
+	noServer := conf.BaseURL == "" && conf.Transport == nil && !local
+	if noServer {
+		logger.Info("No telemetry server configured, telemetry is disabled")
+	}
+
+	// The client is only created once the first event is sent.
+	client := newLazyClient(func() eventClient {
+		client := newClient(logger, conf)
+		if len(conf.KindRoutes) > 0 && !noServer && !local && conf.Transport == nil {
+			client = newKindRouter(logger, conf, client)
+		}
+
+		return client
+	})
+
+	return newReporter(ctx, logger, conf, client, noServer), nil
+}
+
+// validateConfiguration checks that the configuration is usable. A
+// configuration without a telemetry server is valid.
+func validateConfiguration(conf Configuration) error {
+	if err := validateEventPatterns(conf); err != nil {
+		return err
+	}
+
+	local := conf.DryRun || conf.ConsoleWriter != nil
+	if local || conf.Transport != nil || conf.BaseURL == "" {
+		return nil
+	}
+
+	if err := validateBaseURL(conf.BaseURL); err != nil {
+		return err
+	}
+
+	if err := validateKindRoutes(conf.KindRoutes); err != nil {
+		return err
+	}
+
+	if err := validateBatchEncoding(conf); err != nil {
+		return err
+	}
+
+	return validateTLS(conf)
+}
+
+// loggerOrDiscard returns the logger, or if it is nil, a logger that discards
+// every record.
+func loggerOrDiscard(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	return logger
+}
+
+// newClient creates the client used to send events to the telemetry server
+// described by the configuration.
+func newClient(logger *slog.Logger, conf Configuration) eventClient {
+	if conf.ConsoleWriter != nil {
+		return &consoleClient{w: conf.ConsoleWriter, format: conf.ConsoleFormat}
+	}
+
+	if conf.DryRun {
+		return &dryRunClient{logger: logger, marshal: conf.Marshaler}
+	}
+
+	if conf.Transport != nil {
+		return conf.Transport
+	}
+
 	httpClient := conf.HTTPClient
 	if httpClient == nil {
-		httpClient = http.DefaultClient
+		var err error
+		httpClient, err = createHTTPClient(logger, conf)
+		if err != nil {
+			// Falling back to a default client would silently drop the TLS
+			// configuration, so every report fails instead.
+			logger.Error("Failed to create HTTP client", slog.Any("error", err))
+
+			return &failedClient{err: err}
+		}
+	}
+
+	userAgent := v1alpha1.DefaultUserAgent()
+	if conf.UserAgent != "" {
+		userAgent = conf.UserAgent + " " + userAgent
+	}
+
+	baseURL := conf.BaseURL
+	if _, ok := unixSocketPath(baseURL); ok && conf.HTTPClient == nil {
+		// The transport dials the socket, so the host is just a placeholder.
+		baseURL = "http://localhost"
+	}
+
+	return v1alpha1.NewTelemetryEventClient(httpClient, baseURL, v1alpha1.ClientOptions{
+		MaxRetries:             conf.MaxRetries,
+		Backoff:                conf.Backoff,
+		RequestHook:            conf.RequestHook,
+		RequestSigner:          conf.RequestSigner,
+		ValidateResponse:       conf.ValidateResponse,
+		ValidateBatchResponse:  conf.ValidateBatchResponse,
+		StreamBatches:          conf.StreamBatches,
+		Marshaler:              conf.Marshaler,
+		BatchMarshaler:         conf.BatchMarshaler,
+		ContentType:            conf.ContentType,
+		UserAgent:              userAgent,
+		HealthCheckPath:        conf.HealthCheckPath,
+		DisableIdempotencyKeys: conf.DisableIdempotencyKeys,
+		MaxErrorBodyBytes:      conf.MaxErrorBodyBytes,
+	})
+}
+
+// failedClient fails every report with the error that prevented the client
+// from being created.
+type failedClient struct {
+	err error
+}
+
+func (c *failedClient) ReportEvent(_ context.Context, _ *v1alpha1.TelemetryEvent) error {
+	return c.err
+}
+
+func (c *failedClient) ReportEvents(_ context.Context, _ []*v1alpha1.TelemetryEvent) error {
+	return c.err
+}
+
+// lifecycle ensures the reporter is only shut down (or closed) once.
+type lifecycle struct {
+	shutdownOnce sync.Once
+	shutdownErr  error
+	closeOnce    sync.Once
+	closeErr     error
+	closed       atomic.Bool
+	// Stops the reporter being closed once its parent context is cancelled.
+	stopAfter func() bool
+}
+
+func newReporter(ctx context.Context, logger *slog.Logger, conf Configuration, client eventClient, noServer bool) *Reporter {
+	clock := conf.Clock
+	if clock == nil {
+		clock = systemClock{}
+	}
+
+	failureLevel := conf.FailureLogLevel
+	if failureLevel == nil {
+		failureLevel = slog.LevelDebug
+	}
+
+	parentCtx := ctx
+	ctx, cancel := context.WithCancel(ctx)
+
+	doNotTrack := lookupDoNotTrack()
+
+	schemaVersion := conf.SchemaVersion
+	if schemaVersion == "" {
+		schemaVersion = v1alpha1.SchemaVersion
+	}
+
+	redact := conf.MessageRedactor
+	if redact == nil {
+		redact = DefaultMessageRedactor
+	}
+
+	idLength := conf.IDLength
+	if idLength <= 0 {
+		idLength = defaultIDLength
 	}
 
-	reports, reportsCtx := errgroup.WithContext(ctx)
-	reports.SetLimit(maxConcurrentReports)
+	maxStackDepth := conf.MaxStackDepth
+	if maxStackDepth <= 0 {
+		maxStackDepth = defaultMaxStackDepth
+	}
+
+	generateID := conf.IDGenerator
+	if generateID == nil {
+		generateID = func() string { return util.GenerateID(idLength) }
+	}
 
-	return &Reporter{
-		logger:     logger,
-		client:     v1alpha1.NewTelemetryEventClient(httpClient, conf.BaseURL),
-		sessionID:  util.GenerateID(16),
-		tags:       conf.Tags,
-		reportsCtx: reportsCtx,
-		reports:    reports,
+	r := &Reporter{
+		logger:       logger,
+		client:       client,
+		session:      newSession(generateID),
+		installID:    newInstallID(logger, conf.InstallID, conf.InstallIDFile, generateID),
+		doNotTrack:   doNotTrack,
+		noServer:     noServer,
+		console:      conf.ConsoleWriter != nil,
+		disabledInCI: !conf.AllowInCI && runningInCI(),
+		failureLevel: failureLevel,
+		tags:         conf.Tags,
+		sampler:      newSampler(conf.SampleRate, conf.TagSampleRates),
+		adaptive:     newAdaptiveSampler(conf.TargetEventsPerSecond),
+		filter:       newEventFilter(conf.AllowedEvents, conf.BlockedEvents),
+		valueKeys:    newValueKeys(conf.AllowedValueKeys),
+		timestamps:   newTimestamper(clock, conf.TimestampPrecision),
+		warmup:       newWarmup(clock.Now(), conf),
+		barrier:      newBarrier(),
+		startup:      newStartupDelay(conf.StartupJitter),
+		pacer:        newPacer(conf.SendInterval),
+		redact:       redact,
+		schemaVer:    schemaVersion,
+		maxPayload:   conf.MaxPayloadBytes,
+		maxValues:    conf.MaxValues,
+		maxValueLen:  conf.MaxValueLength,
+		maxTags:      conf.MaxTags,
+		maxNameLen:   conf.MaxNameLength,
+		maxMsgLen:    conf.MaxMessageLength,
+		stackDepth:   maxStackDepth,
+		maxAge:       conf.MaxEventAge,
+		breadcrumbs:  newBreadcrumbs(conf.MaxBreadcrumbs),
+		runtimeStats: newRuntimeStats(conf.RuntimeStats, conf.RuntimeStatsKinds),
+		paths:        newPathTrimmer(append(defaultTrimPathPrefixes(), conf.TrimPathPrefix...)),
+		teeCh:        conf.Tee,
+		onDrop:       conf.OnDrop,
+		middleware:   conf.Middleware,
+		stats:        newStats(),
+		maxRetries:   conf.MaxRetries,
+		reportsCtx:   ctx,
+		cancel:       cancel,
+		lifecycle:    &lifecycle{},
+		queue:        newQueue(maxConcurrentReports, conf.QueueSize, conf.ReservedHighPrioritySlots),
+		blockTimeout: conf.BlockTimeout,
+		disabled:     &atomic.Bool{},
+		paused:       &atomic.Bool{},
+		shuttingDown: &atomic.Bool{},
 	}
+
+	if conf.Enabled != nil && !*conf.Enabled {
+		r.disabled.Store(true)
+	}
+
+	r.counters = newCounters(conf.CounterFlushInterval, r.ReportEvent)
+
+	r.saturation = newSaturation(conf.SaturationThreshold, conf.SaturationWindow, clock, r.ReportEvent)
+
+	r.heartbeat = newHeartbeat(conf.HeartbeatInterval, clock, r.ReportEvent)
+	if !doNotTrack && !noServer {
+		r.heartbeat.start()
+	}
+
+	if conf.BatchSize > 1 {
+		r.batcher = newBatcher(conf.BatchSize, conf.MaxBatchBytes, conf.BatchInterval, conf.Marshaler, r.sendBatch)
+	}
+
+	// Once the parent context is cancelled, in-flight reports are aborted and
+	// nothing more can be sent, so stop accepting events.
+	r.lifecycle.stopAfter = context.AfterFunc(parentCtx, func() {
+		_ = r.closeOnce()
+	})
+
+	return r
 }
 
-// Close aborts any ongoing telemetry reporting.
+// Close aborts any ongoing telemetry reporting. Events that have not yet been
+// sent are dropped. It is safe to call Close more than once, and after
+// Shutdown.
 func (r *Reporter) Close() error {
-	r.reports.Go(func() error {
-		return context.Canceled
+	r.lifecycle.stopAfter()
+
+	return r.closeOnce()
+}
+
+func (r *Reporter) closeOnce() error {
+	r.lifecycle.closeOnce.Do(func() {
+		r.lifecycle.closed.Store(true)
+		r.lifecycle.closeErr = r.close()
 	})
 
-	if err := r.reports.Wait(); err != nil && !errors.Is(err, context.Canceled) {
-		return err
+	return r.lifecycle.closeErr
+}
+
+// CloseWithin stops accepting new events and gives in-flight (and queued)
+// reports until the context expires to finish, before aborting any that
+// remain as Close does. Unlike Shutdown, aggregated counters and buffered
+// batches are dropped rather than sent. It is safe to call more than once,
+// and after Close or Shutdown.
+func (r *Reporter) CloseWithin(ctx context.Context) error {
+	r.lifecycle.stopAfter()
+
+	r.lifecycle.closeOnce.Do(func() {
+		r.lifecycle.closed.Store(true)
+		r.lifecycle.closeErr = r.closeWithin(ctx)
+	})
+
+	return r.lifecycle.closeErr
+}
+
+func (r *Reporter) closeWithin(ctx context.Context) error {
+	// Stop accepting new reports.
+	r.shuttingDown.Store(true)
+	r.counters.stop()
+	r.heartbeat.stop()
+
+	if r.batcher != nil {
+		r.dropBatch(r.batcher.stop(), DropReasonShuttingDown)
+	}
+
+	// There's no point holding back events any longer.
+	r.startup.release()
+
+	r.queue.close()
+
+	reportsDone := make(chan struct{})
+	go func() {
+		defer close(reportsDone)
+
+		r.queue.wait()
+	}()
+
+	select {
+	case <-ctx.Done():
+	case <-reportsDone:
 	}
 
+	// Abort any reports that didn't finish in time.
+	return r.close()
+}
+
+func (r *Reporter) close() error {
+	r.shuttingDown.Store(true)
+	r.counters.stop()
+	r.heartbeat.stop()
+
+	if r.batcher != nil {
+		r.dropBatch(r.batcher.stop(), DropReasonShuttingDown)
+	}
+
+	r.queue.close()
+	r.cancel()
+
+	r.queue.wait()
+	r.queue.stopWorkers()
+
+	// Stop the startup timer, if it's still pending.
+	r.startup.release()
+
 	return nil
 }
 
-// Shutdown gracefully shuts down the telemetry reporter.
+// Shutdown gracefully shuts down the telemetry reporter. Aggregated counters
+// are reported, new events are rejected, any buffered batch is flushed, and
+// in-flight reports are waited on. If the context expires before then,
+// Shutdown falls back to Close. Subsequent calls return the result of the
+// first, and calling Shutdown after Close does nothing.
 func (r *Reporter) Shutdown(ctx context.Context) error {
+	r.lifecycle.stopAfter()
+
+	r.lifecycle.shutdownOnce.Do(func() {
+		if r.lifecycle.closed.Load() {
+			return
+		}
+
+		r.lifecycle.shutdownErr = r.shutdown(ctx)
+	})
+
+	return r.lifecycle.shutdownErr
+}
+
+func (r *Reporter) shutdown(ctx context.Context) error {
+	// Report any aggregated counters.
+	r.counters.stop()
+	r.heartbeat.stop()
+	r.counters.flush()
+
 	// Stop accepting new reports.
 	r.shuttingDown.Store(true)
 
-	reportsDone := make(chan error, 1)
+	// There's no point holding back events any longer.
+	r.startup.release()
+
+	// Flush any buffered events.
+	if r.batcher != nil {
+		if batch := r.batcher.stop(); len(batch) > 0 {
+			r.reportBatch(ctx, batch)
+		}
+
+		if ctx.Err() != nil {
+			return r.Close()
+		}
+	}
+
+	// Wait for in-flight (and queued) reports to complete.
+	r.queue.close()
+
+	reportsDone := make(chan struct{})
 	go func() {
 		defer close(reportsDone)
 
-		reportsDone <- r.reports.Wait()
+		r.queue.wait()
 	}()
 
 	select {
 	case <-ctx.Done():
 		// Abort any ongoing reports.
 		return r.Close()
-	case err := <-reportsDone:
-		if err != nil && !errors.Is(err, context.Canceled) {
-			return err
-		}
+	case <-reportsDone:
+		r.queue.stopWorkers()
+
+		// Release the reporter's context.
+		r.cancel()
 
 		return nil
 	}
 }
 
-// ReportEvent reports a telemetry event.
+// Enable enables telemetry reporting, unless it is disabled by DO_NOT_TRACK.
+func (r *Reporter) Enable() {
+	r.disabled.Store(false)
+}
+
+// Disable disables telemetry reporting, subsequent events will be dropped.
+// Events already queued will still be sent.
+func (r *Reporter) Disable() {
+	r.disabled.Store(true)
+}
+
+// Pause temporarily stops reporting (eg. during a benchmark), subsequent events
+// are dropped with DropReasonPaused until Resume is called. Events already
+// queued will still be sent.
+func (r *Reporter) Pause() {
+	r.paused.Store(true)
+}
+
+// Resume resumes reporting after a call to Pause.
+func (r *Reporter) Resume() {
+	r.paused.Store(false)
+}
+
+// ReportEvent reports a telemetry event. The event is copied before it is
+// enriched, so the caller is free to reuse it once ReportEvent returns. The
+// event is stamped with the current time, unless it sets its own Timestamp.
 func (r *Reporter) ReportEvent(event *v1alpha1.TelemetryEvent) {
-	if os.Getenv(doNotTrackEnvName) != "" {
-		r.logger.Debug("Telemetry is disabled, dropping event")
+	r.ReportEventCtx(context.Background(), event)
+}
+
+// ReportEventCtx reports a telemetry event, merging in any values attached to
+// the context with WithValues. The context is only used for its values (eg.
+// the trace context propagated with the upload), the event is still sent
+// asynchronously, so cancelling the context has no effect.
+func (r *Reporter) ReportEventCtx(ctx context.Context, event *v1alpha1.TelemetryEvent) {
+	event, ok := r.prepare(ctx, event)
+	if !ok {
 		return
 	}
 
-	now := time.Now()
-	event.Timestamp = &now
+	if r.shuttingDown.Load() {
+		r.logger.Debug("Shutting down, dropping event")
+		r.dropped(event, DropReasonShuttingDown)
+		return
+	}
 
-	if event.SessionID == "" {
-		event.SessionID = r.sessionID
+	// Console output is written synchronously, so it is never dropped because
+	// the queue is full.
+	if r.console {
+		ctx := context.WithoutCancel(ctx)
+		if err := r.reportSync(ctx, event); err != nil {
+			r.logFailure(ctx, "Failed to report event", err, slog.String("name", event.Name))
+		}
+
+		return
 	}
 
-	event.Tags = append(event.Tags, r.tags...)
+	teed := r.teeCopy(event)
 
-	if r.shuttingDown.Load() {
-		r.logger.Debug("Shutting down, dropping event")
+	gate, done := r.barrier.enter()
+
+	if r.batcher != nil {
+		if !r.batcher.add(batchedEvent{event: event, gate: gate, done: done}) {
+			done()
+
+			r.logger.Debug("Shutting down, dropping event")
+			r.dropped(event, DropReasonShuttingDown)
+			return
+		}
+
+		r.tee(teed)
 		return
 	}
 
-	started := r.reports.TryGo(func() error {
+	started := r.tryGo(event.HighPriority(), func() {
+		defer done()
+
+		// Wait for any events preceding a barrier to be sent.
+		select {
+		case <-r.reportsCtx.Done():
+			r.dropped(event, DropReasonShuttingDown)
+			return
+		case <-gate:
+		}
+
+		if err := r.startup.wait(r.reportsCtx); err != nil {
+			r.dropped(event, DropReasonShuttingDown)
+			return
+		}
+
+		if err := r.pacer.wait(r.reportsCtx); err != nil {
+			r.dropped(event, DropReasonShuttingDown)
+			return
+		}
+
+		if r.expired(event) {
+			r.logger.Debug("Event is too old, dropping event")
+			r.dropped(event, DropReasonExpired)
+			return
+		}
+
 		// Absolute maximum limit.
-		ctx, cancel := context.WithTimeout(r.reportsCtx, 30*time.Second)
+		ctx, cancel := context.WithTimeout(mergeValues(r.reportsCtx, ctx), 30*time.Second)
 		defer cancel()
 
-		if err := r.client.ReportEvent(ctx, event); err != nil {
-			// Don't spam the logs when the user is offline.
-			r.logger.Debug("Failed to report event", slog.Any("error", err))
+		sent, err := r.sendEvent(ctx, event)
+		if !sent {
+			return
 		}
 
+		if err != nil {
+			r.stats.addFailed(err)
+			r.logFailure(ctx, "Failed to report event", err, slog.String("name", event.Name))
+		} else {
+			r.stats.addReported()
+		}
+	})
+	if !started {
+		done()
+
+		r.logger.Warn("Telemetry queue is full, dropping event")
+		r.dropped(event, DropReasonQueueFull)
+		return
+	}
+
+	r.tee(teed)
+}
+
+// mergedContext is a context whose values are looked up in values first,
+// falling back to the parent context, which alone controls cancellation.
+type mergedContext struct {
+	context.Context
+	values context.Context
+}
+
+// mergeValues returns a copy of parent which also carries the values of ctx
+// (eg. the caller's trace context), without inheriting its cancellation.
+func mergeValues(parent, ctx context.Context) context.Context {
+	return &mergedContext{Context: parent, values: context.WithoutCancel(ctx)}
+}
+
+func (c *mergedContext) Value(key any) any {
+	if v := c.values.Value(key); v != nil {
+		return v
+	}
+
+	return c.Context.Value(key)
+}
+
+// ReportEventSync reports a telemetry event and waits for it to be sent,
+// returning any error encountered. Events dropped because telemetry is
+// disabled or not sampled are not considered errors. Any values attached to
+// the context with WithValues are merged into the event. Synchronous reports
+// are not subject to SendInterval or StartupJitter.
+func (r *Reporter) ReportEventSync(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+	event, ok := r.prepare(ctx, event)
+	if !ok {
+		return nil
+	}
+
+	if r.shuttingDown.Load() {
+		return ErrShuttingDown
+	}
+
+	return r.reportSync(ctx, event)
+}
+
+// reportSync sends a prepared event, waiting for it to be sent.
+func (r *Reporter) reportSync(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+	r.tee(r.teeCopy(event))
+
+	gate, done := r.barrier.enter()
+	defer done()
+
+	// Wait for any events preceding a barrier to be sent.
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-gate:
+	}
+
+	if r.expired(event) {
+		r.logger.Debug("Event is too old, dropping event")
+		r.dropped(event, DropReasonExpired)
 		return nil
+	}
+
+	sent, err := r.sendEvent(ctx, event)
+	if !sent {
+		return nil
+	} else if err != nil {
+		r.stats.addFailed(err)
+		return err
+	}
+
+	r.stats.addReported()
+
+	return nil
+}
+
+// prepare copies and enriches an event prior to sending it. If the event
+// should be dropped, ok is false.
+func (r *Reporter) prepare(ctx context.Context, event *v1alpha1.TelemetryEvent) (_ *v1alpha1.TelemetryEvent, ok bool) {
+	if event == nil {
+		r.logger.Debug("Event is nil, dropping event")
+		r.dropped(nil, DropReasonInvalid)
+		return nil, false
+	}
+
+	if r.doNotTrack {
+		r.logger.Debug("Telemetry is disabled, dropping event")
+		r.dropped(event, DropReasonDisabled)
+		return nil, false
+	}
+
+	if r.noServer {
+		r.logger.Debug("No telemetry server configured, dropping event")
+		r.dropped(event, DropReasonDisabled)
+		return nil, false
+	}
+
+	if r.disabled.Load() {
+		r.logger.Debug("Telemetry is disabled, dropping event")
+		r.dropped(event, DropReasonDisabled)
+		return nil, false
+	}
+
+	if r.paused.Load() {
+		r.logger.Debug("Telemetry is paused, dropping event")
+		r.dropped(event, DropReasonPaused)
+		return nil, false
+	}
+
+	if r.disabledInCI {
+		r.logger.Debug("Running in CI, dropping event")
+		r.dropped(event, DropReasonDisabled)
+		return nil, false
+	}
+
+	if !r.filter.allow(event.Name) {
+		r.logger.Debug("Event is filtered, dropping event", slog.String("name", event.Name))
+		r.dropped(event, DropReasonFiltered)
+		return nil, false
+	}
+
+	event = event.DeepCopy()
+
+	if event.SchemaVersion == "" {
+		event.SchemaVersion = r.schemaVer
+	}
+
+	mergeContextValues(ctx, event)
+	mergeContextIDs(ctx, event)
+
+	if stripped := r.valueKeys.strip(event); len(stripped) > 0 {
+		r.logger.Warn("Event has values that are not allowed, removing them", slog.String("name", event.Name), slog.Any("keys", stripped))
+	}
+
+	if event.Message != "" {
+		event.Message = r.redact(event.Message)
+	}
+
+	limitValues(event, r.maxValues, r.maxValueLen)
+	limitText(event, r.maxNameLen, r.maxMsgLen)
+
+	r.paths.trim(event.StackTrace)
+
+	now, ok := r.timestamps.now()
+
+	// Events that set their own timestamp (eg. when backfilling historical
+	// events) keep it.
+	timestamp := now
+	if event.Timestamp != nil {
+		timestamp = *event.Timestamp
+	} else if !ok {
+		setValue(event, clockAnomalyValueKey, "true")
+	}
+
+	timestamp = r.timestamps.truncate(timestamp)
+	event.Timestamp = &timestamp
+
+	if r.warmup.suppress(event, now) {
+		r.logger.Debug("Warming up, dropping event")
+		r.dropped(event, DropReasonSuppressed)
+		return nil, false
+	}
+
+	if event.Kind == "" {
+		event.Kind = v1alpha1.TelemetryEventKindInfo
+	}
+
+	r.breadcrumbs.record(event, now)
+
+	if event.SessionID == "" {
+		event.SessionID = r.session.current()
+	}
+
+	if id := r.installID.get(); id != "" {
+		if _, ok := event.Values[installIDValueKey]; !ok {
+			setValue(event, installIDValueKey, id)
+		}
+	}
+
+	if !reporterTagsDisabled(ctx) {
+		event.Tags = mergeTags(event.Tags, r.tags)
+	}
+
+	if tags, truncated := limitTags(event.Tags, r.maxTags); truncated {
+		r.logger.Warn("Event has too many tags, truncating", slog.String("name", event.Name), slog.Int("count", len(event.Tags)))
+		event.Tags = tags
+		setValue(event, truncatedValueKey, "true")
+	}
+
+	if !r.sampler.sample(event.Tags) {
+		r.logger.Debug("Event not sampled, dropping event")
+		r.dropped(event, DropReasonSampled)
+		return nil, false
+	}
+
+	if !r.adaptive.sample(event.Kind, now) {
+		r.logger.Debug("Event rate exceeds target, dropping event")
+		r.dropped(event, DropReasonSampled)
+		return nil, false
+	}
+
+	r.runtimeStats.enrich(event)
+
+	if r.maxPayload > 0 {
+		fits, err := truncateEvent(event, r.maxPayload)
+		if err != nil {
+			r.logger.Warn("Failed to marshal event, dropping event", slog.Any("error", err))
+			r.dropped(event, DropReasonTooLarge)
+			return nil, false
+		} else if !fits {
+			r.logger.Warn("Event exceeds maximum payload size, dropping event")
+			r.dropped(event, DropReasonTooLarge)
+			return nil, false
+		}
+	}
+
+	return event, true
+}
+
+// Barrier waits for every event reported before the call to be sent. Events
+// reported after the call are held back until the barrier is complete, so
+// they will never be sent before the events preceding the barrier.
+func (r *Reporter) Barrier(ctx context.Context) error {
+	flushed := r.barrier.next()
+
+	// Don't wait for the batch interval to elapse.
+	if r.batcher != nil {
+		r.batcher.flush()
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-flushed:
+		return nil
+	}
+}
+
+// sendBatch asynchronously sends a batch of events.
+func (r *Reporter) sendBatch(batch []batchedEvent) {
+	highPriority := slices.ContainsFunc(batch, func(e batchedEvent) bool {
+		return e.event.HighPriority()
+	})
+
+	started := r.tryGo(highPriority, func() {
+		r.reportBatch(r.reportsCtx, batch)
 	})
 	if !started {
-		r.logger.Warn("Too many in-flight telemetry reports, dropping event")
+		r.logger.Warn("Telemetry queue is full, dropping events", slog.Int("count", len(batch)))
+		r.dropBatch(batch, DropReasonQueueFull)
+	}
+}
+
+// reportBatch sends a batch of events, once any preceding barriers allow.
+// Rejected events are retried as part of a later batch. If ctx is cancelled
+// before the batch can be sent, the events are dropped.
+func (r *Reporter) reportBatch(ctx context.Context, batch []batchedEvent) {
+	// Wait for any events preceding a barrier to be sent.
+	for _, e := range batch {
+		select {
+		case <-ctx.Done():
+			r.dropBatch(batch, DropReasonShuttingDown)
+			return
+		case <-e.gate:
+		}
+	}
+
+	if err := r.startup.wait(ctx); err != nil {
+		r.dropBatch(batch, DropReasonShuttingDown)
+		return
+	}
+
+	if err := r.pacer.wait(ctx); err != nil {
+		r.dropBatch(batch, DropReasonShuttingDown)
+		return
+	}
+
+	batch = r.applyMiddleware(ctx, r.dropExpired(batch))
+	if len(batch) == 0 {
+		return
+	}
+
+	// Absolute maximum limit.
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	events := make([]*v1alpha1.TelemetryEvent, len(batch))
+	for i, e := range batch {
+		events[i] = e.event
 	}
+
+	err := r.reportEvents(ctx, events)
+	if err != nil {
+		r.logFailure(ctx, "Failed to report events", err, slog.Int("count", len(events)))
+	}
+
+	rejected, failed := batchFailures(err)
+
+	for i, e := range batch {
+		if reason, ok := rejected[i]; ok {
+			if e.attempts < r.maxRetries {
+				// Retry the rejected event as part of a later batch.
+				e.attempts++
+				if r.batcher.add(e) {
+					continue
+				}
+			}
+
+			setValue(e.event, rejectedReasonValueKey, reason)
+
+			e.done()
+			r.dropped(e.event, DropReasonRejected)
+			continue
+		}
+
+		if err := failed(i); err != nil {
+			r.stats.addFailed(err)
+		} else {
+			r.stats.addReported()
+		}
+
+		e.done()
+	}
+}
+
+// dropBatch drops every event in the batch.
+func (r *Reporter) dropBatch(batch []batchedEvent, reason DropReason) {
+	doneAll(batch)
+
+	for _, e := range batch {
+		r.dropped(e.event, reason)
+	}
+}
+
+// logFailure logs a failure to send events at the configured level.
+func (r *Reporter) logFailure(ctx context.Context, msg string, err error, attrs ...slog.Attr) {
+	attrs = append(attrs, slog.Any("error", err))
+
+	var httpErr *v1alpha1.HTTPError
+	if errors.As(err, &httpErr) {
+		attrs = append(attrs, slog.Int("status", httpErr.StatusCode))
+	}
+
+	r.logger.LogAttrs(ctx, r.failureLevel.Level(), msg, attrs...)
 }
@@ -11,10 +11,12 @@ package telemetry_test
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"sync"
 	"testing"
 	"time"
 
@@ -55,6 +57,7 @@ func TestReporter(t *testing.T) {
 		assert.Equal(t, "test-tag", event.Tags[0])
 		assert.NotEmpty(t, event.SessionID, "SessionID should be set")
 		assert.NotNil(t, event.Timestamp, "Timestamp should be set")
+		assert.Equal(t, v1alpha1.SchemaVersion, event.SchemaVersion)
 
 	case <-time.After(1 * time.Second):
 		t.Fatal("Timeout waiting for telemetry event")
@@ -64,12 +67,196 @@ func TestReporter(t *testing.T) {
 	require.NoError(t, reporter.Shutdown(ctx))
 }
 
+func TestReporter_NilEvent(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	var dropped []telemetry.DropReason
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		OnDrop: func(event *v1alpha1.TelemetryEvent, reason telemetry.DropReason) {
+			assert.Nil(t, event)
+			dropped = append(dropped, reason)
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	require.NotPanics(t, func() {
+		reporter.ReportEvent(nil)
+		require.NoError(t, reporter.ReportEventSync(ctx, nil))
+	})
+
+	assert.Equal(t, []telemetry.DropReason{
+		telemetry.DropReasonInvalid,
+		telemetry.DropReasonInvalid,
+	}, dropped)
+
+	// Events without values should still be enriched.
+	valuesCtx := telemetry.WithValues(ctx, map[string]string{"request_id": "abc123"})
+
+	require.NotPanics(t, func() {
+		require.NoError(t, reporter.ReportEventSync(valuesCtx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		}))
+	})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, map[string]string{"request_id": "abc123"}, event.Values)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_IDGenerator(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		IDGenerator: func() string {
+			return "test-session"
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	}))
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "test-session", event.SessionID)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_RotateSession(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	firstSessionID := reporter.SessionID()
+	require.NotEmpty(t, firstSessionID)
+
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Name: "BeforeRotation",
+	}))
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, firstSessionID, event.SessionID)
+
+	reporter.RotateSession()
+
+	secondSessionID := reporter.SessionID()
+	assert.NotEqual(t, firstSessionID, secondSessionID)
+
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Name: "AfterRotation",
+	}))
+
+	event = receiveEvent(t, eventCh)
+	assert.Equal(t, secondSessionID, event.SessionID)
+
+	// Child reporters share the session.
+	assert.Equal(t, secondSessionID, reporter.WithTags("child").SessionID())
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_UserAgent(t *testing.T) {
+	userAgentCh := make(chan string, 1)
+
+	// Start a mock telemetry server.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgentCh <- r.UserAgent()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:   server.URL,
+		UserAgent: "myapp/1.2.3",
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	}))
+
+	assert.Equal(t, "myapp/1.2.3 "+v1alpha1.DefaultUserAgent(), <-userAgentCh)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_ContentType(t *testing.T) {
+	type request struct {
+		contentType string
+		body        string
+	}
+	requestCh := make(chan request, 1)
+
+	// Start a mock telemetry server.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		requestCh <- request{
+			contentType: r.Header.Get("Content-Type"),
+			body:        string(body),
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter with a custom serialization.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Marshaler: func(event *v1alpha1.TelemetryEvent) ([]byte, error) {
+			return []byte("name=" + event.Name), nil
+		},
+		ContentType: "application/x-www-form-urlencoded",
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	}))
+
+	req := <-requestCh
+	assert.Equal(t, "application/x-www-form-urlencoded", req.contentType)
+	assert.Equal(t, "name=TestEvent", req.body)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
 func TestReporter_DoNotTrack(t *testing.T) {
 	// Set the DO_NOT_TRACK environment variable.
-	os.Setenv("DO_NOT_TRACK", "1")
-	t.Cleanup(func() {
-		os.Unsetenv("DO_NOT_TRACK")
-	})
+	t.Setenv("DO_NOT_TRACK", "1")
 
 	// Start a mock telemetry server.
 	server, eventCh := mockTelemetryServer(t)
@@ -105,6 +292,396 @@ func TestReporter_DoNotTrack(t *testing.T) {
 	require.NoError(t, reporter.Shutdown(ctx))
 }
 
+func TestReporter_DoNotTrackOverride(t *testing.T) {
+	t.Run("Disabled", func(t *testing.T) {
+		// Start a mock telemetry server.
+		server, eventCh := mockTelemetryServer(t)
+		t.Cleanup(server.Close)
+
+		t.Cleanup(telemetry.SetDoNotTrack(true))
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL: server.URL,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		// DO_NOT_TRACK takes precedence over enabling telemetry.
+		reporter.Enable()
+
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		}))
+
+		select {
+		case event := <-eventCh:
+			t.Fatalf("Expected no telemetry event, but got: %v", event)
+		default:
+		}
+
+		// Shutdown the reporter to ensure graceful exit.
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		t.Setenv("DO_NOT_TRACK", "1")
+
+		// Start a mock telemetry server.
+		server, eventCh := mockTelemetryServer(t)
+		t.Cleanup(server.Close)
+
+		t.Cleanup(telemetry.SetDoNotTrack(false))
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL: server.URL,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		}))
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "TestEvent", event.Name)
+
+		// Shutdown the reporter to ensure graceful exit.
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+}
+
+func TestReporter_Enabled(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	var mu sync.Mutex
+	var dropped []telemetry.DropReason
+
+	// Create a new telemetry reporter that starts out disabled.
+	enabled := false
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Enabled: &enabled,
+		OnDrop: func(_ *v1alpha1.TelemetryEvent, reason telemetry.DropReason) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			dropped = append(dropped, reason)
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "BeforeConsent"})
+
+	reporter.Enable()
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "AfterConsent"})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "AfterConsent", event.Name)
+
+	reporter.Disable()
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "AfterRevoked"})
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no further telemetry events, but got: %v", event)
+	default:
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []telemetry.DropReason{
+		telemetry.DropReasonDisabled,
+		telemetry.DropReasonDisabled,
+	}, dropped)
+}
+
+func TestReporter_Pause(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	var mu sync.Mutex
+	var dropped []string
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		OnDrop: func(event *v1alpha1.TelemetryEvent, reason telemetry.DropReason) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			assert.Equal(t, telemetry.DropReasonPaused, reason)
+			dropped = append(dropped, event.Name)
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.Pause()
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "WhilePaused"})
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{Name: "WhilePausedSync"}))
+
+	reporter.Resume()
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "AfterResume"})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "AfterResume", event.Name)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no further telemetry events, but got: %v", event)
+	default:
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	assert.Equal(t, []string{"WhilePaused", "WhilePausedSync"}, dropped)
+	assert.Equal(t, uint64(2), reporter.Stats().Dropped[telemetry.DropReasonPaused])
+}
+
+func TestReporter_PauseConcurrent(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	go func() {
+		for range eventCh {
+		}
+	}()
+
+	conf := telemetry.Configuration{
+		BaseURL:   server.URL,
+		QueueSize: 1000,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// Pausing is safe concurrently with reporting.
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			reporter.Pause()
+			reporter.Resume()
+		}
+	}()
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "TestEvent"})
+		}
+	}()
+	wg.Wait()
+
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_TagSampleRates(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter that drops everything tagged "debug".
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		TagSampleRates: map[string]float64{
+			"debug": 0,
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// Report a telemetry event that should be dropped.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "DebugEvent",
+		Tags: []string{"debug"},
+	})
+
+	// Report a telemetry event that should use the global rate.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	select {
+	case event := <-eventCh:
+		assert.Equal(t, "TestEvent", event.Name)
+
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout waiting for telemetry event")
+	}
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no further telemetry events, but got: %v", event)
+	default:
+	}
+}
+
+func TestReporter_ReportEventSync(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// Start a mock telemetry server.
+		server, eventCh := mockTelemetryServer(t)
+		t.Cleanup(server.Close)
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL: server.URL,
+			Tags:    []string{"test-tag"},
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		}))
+
+		// The event should have already been received.
+		select {
+		case event := <-eventCh:
+			assert.Equal(t, "TestEvent", event.Name)
+			assert.Equal(t, []string{"test-tag"}, event.Tags)
+			assert.NotEmpty(t, event.SessionID, "SessionID should be set")
+		default:
+			t.Fatal("Expected telemetry event to have been received")
+		}
+
+		// Shutdown the reporter, subsequent events should be rejected.
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		err := reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+		require.ErrorIs(t, err, telemetry.ErrShuttingDown)
+	})
+
+	t.Run("Server Error", func(t *testing.T) {
+		// Start a mock telemetry server that always fails.
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(server.Close)
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL: server.URL,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		err := reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "500")
+
+		// Shutdown the reporter to ensure graceful exit.
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+
+	t.Run("Do Not Track", func(t *testing.T) {
+		t.Setenv("DO_NOT_TRACK", "1")
+
+		// Start a mock telemetry server.
+		server, eventCh := mockTelemetryServer(t)
+		t.Cleanup(server.Close)
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL: server.URL,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		}))
+
+		select {
+		case event := <-eventCh:
+			t.Fatalf("Expected no telemetry event, but got: %v", event)
+		default:
+		}
+
+		// Shutdown the reporter to ensure graceful exit.
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+}
+
+func TestReporter_FailureLogLevel(t *testing.T) {
+	// Start a mock telemetry server that always fails.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(server.Close)
+
+	handler := &recordingHandler{}
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:         server.URL,
+		FailureLogLevel: slog.LevelWarn,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.New(handler), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	// Shutdown the reporter to wait for the event to be sent.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	records := handler.Records()
+	require.Len(t, records, 1)
+
+	record := records[0]
+	assert.Equal(t, slog.LevelWarn, record.Level)
+	assert.Equal(t, "Failed to report event", record.Message)
+
+	attrs := map[string]slog.Value{}
+	record.Attrs(func(attr slog.Attr) bool {
+		attrs[attr.Key] = attr.Value
+		return true
+	})
+
+	assert.Equal(t, "TestEvent", attrs["name"].String())
+	assert.Equal(t, int64(http.StatusServiceUnavailable), attrs["status"].Int64())
+	assert.Contains(t, attrs, "error")
+}
+
 func TestReporter_EndToEnd(t *testing.T) {
 	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: slog.LevelDebug,
@@ -133,10 +710,18 @@ func TestReporter_EndToEnd(t *testing.T) {
 }
 
 func mockTelemetryServer(t *testing.T) (*httptest.Server, chan *v1alpha1.TelemetryEvent) {
-	eventCh := make(chan *v1alpha1.TelemetryEvent, 1)
+	handler, eventCh := mockTelemetryHandler(t)
 
 	// Create a mock server to handle incoming telemetry events.
-	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	server := httptest.NewServer(handler)
+
+	return server, eventCh
+}
+
+func mockTelemetryHandler(t *testing.T) (http.Handler, chan *v1alpha1.TelemetryEvent) {
+	eventCh := make(chan *v1alpha1.TelemetryEvent, 1)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
 
 		require.Equal(t, "/v1alpha1/events", r.URL.Path)
@@ -148,7 +733,188 @@ func mockTelemetryServer(t *testing.T) (*httptest.Server, chan *v1alpha1.Telemet
 		eventCh <- &event
 
 		w.WriteHeader(http.StatusOK)
-	}))
+	})
 
-	return server, eventCh
+	return handler, eventCh
+}
+
+func receiveEvent(t *testing.T, eventCh chan *v1alpha1.TelemetryEvent) *v1alpha1.TelemetryEvent {
+	t.Helper()
+
+	select {
+	case event := <-eventCh:
+		return event
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout waiting for telemetry event")
+		return nil
+	}
+}
+
+// recordingHandler is a slog.Handler that records all log records.
+type recordingHandler struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool {
+	return true
+}
+
+func (h *recordingHandler) Handle(_ context.Context, record slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.records = append(h.records, record.Clone())
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler {
+	return h
+}
+
+func (h *recordingHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+func (h *recordingHandler) Records() []slog.Record {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return append([]slog.Record(nil), h.records...)
+}
+
+func TestReporter_SchemaVersion(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	t.Run("Default", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: server.URL,
+		})
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "TestEvent"})
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "v1alpha1", event.SchemaVersion)
+
+		// Events can override the schema version.
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name:          "TestEvent",
+			SchemaVersion: "v1alpha2",
+		})
+
+		event = receiveEvent(t, eventCh)
+		assert.Equal(t, "v1alpha2", event.SchemaVersion)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL:       server.URL,
+			SchemaVersion: "v1beta1",
+		})
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "TestEvent"})
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "v1beta1", event.SchemaVersion)
+	})
+}
+
+func TestReporter_BaseURL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Empty", func(t *testing.T) {
+		reporter, err := telemetry.NewReporterWithError(ctx, slog.Default(), telemetry.Configuration{})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+
+		// Events are silently dropped, rather than failing to send.
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		}))
+
+		stats := reporter.Stats()
+		assert.Zero(t, stats.Failed)
+		assert.Equal(t, uint64(1), stats.Dropped[telemetry.DropReasonDisabled])
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		for _, baseURL := range []string{
+			"://telemetry.example.com",
+			"telemetry.example.com",
+			"ftp://telemetry.example.com",
+			"https://",
+		} {
+			_, err := telemetry.NewReporterWithError(ctx, slog.Default(), telemetry.Configuration{
+				BaseURL: baseURL,
+			})
+			assert.Error(t, err, baseURL)
+		}
+	})
+
+	t.Run("Malformed Compatibility", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: "telemetry.example.com",
+		})
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+
+		// Telemetry is disabled.
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		}))
+
+		stats := reporter.Stats()
+		assert.Zero(t, stats.Failed)
+		assert.Equal(t, uint64(1), stats.Dropped[telemetry.DropReasonDisabled])
+	})
+}
+
+func TestReporter_NilLogger(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	ctx := context.Background()
+
+	t.Run("Report", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, nil, telemetry.Configuration{
+			BaseURL:      server.URL,
+			WarmupEvents: 1,
+		})
+
+		assert.NotPanics(t, func() {
+			// Dropped (and logged) during warmup.
+			reporter.ReportInfo("WarmupEvent", nil)
+			reporter.ReportInfo("TestEvent", nil)
+		})
+
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, "TestEvent", event.Name)
+
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+
+	t.Run("Invalid Configuration", func(t *testing.T) {
+		assert.NotPanics(t, func() {
+			reporter := telemetry.NewReporter(ctx, nil, telemetry.Configuration{
+				BaseURL: "ftp://telemetry.example.com",
+			})
+			reporter.ReportInfo("TestEvent", nil)
+
+			require.NoError(t, reporter.Shutdown(ctx))
+		})
+	})
 }
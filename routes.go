@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// kindRouter dispatches events to a client based on their kind, falling back
+// to the default client for kinds without a route.
+type kindRouter struct {
+	routes   map[v1alpha1.TelemetryEventKind]eventClient
+	fallback eventClient
+}
+
+// newKindRouter creates a client that routes events to the telemetry server
+// configured for their kind. Every route shares the remaining connection
+// options of the configuration.
+func newKindRouter(logger *slog.Logger, conf Configuration, fallback eventClient) *kindRouter {
+	routes := make(map[v1alpha1.TelemetryEventKind]eventClient, len(conf.KindRoutes))
+	for kind, baseURL := range conf.KindRoutes {
+		routeConf := conf
+		routeConf.BaseURL = baseURL
+		routes[kind] = newClient(logger, routeConf)
+	}
+
+	return &kindRouter{routes: routes, fallback: fallback}
+}
+
+// validateKindRoutes checks that every route has a valid base URL.
+func validateKindRoutes(routes map[v1alpha1.TelemetryEventKind]string) error {
+	for kind, baseURL := range routes {
+		if err := validateBaseURL(baseURL); err != nil {
+			return fmt.Errorf("invalid route for %q events: %w", kind, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *kindRouter) client(kind v1alpha1.TelemetryEventKind) eventClient {
+	if client, ok := r.routes[kind]; ok {
+		return client
+	}
+
+	return r.fallback
+}
+
+func (r *kindRouter) ReportEvent(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+	return r.client(event.Kind).ReportEvent(ctx, event)
+}
+
+// ReportEvents splits the batch by destination, sending each part
+// concurrently. If only part of the batch fails to send, a
+// *partialFailureError records which events failed (or were rejected), so
+// that the rest of the batch is counted as reported.
+func (r *kindRouter) ReportEvents(ctx context.Context, events []*v1alpha1.TelemetryEvent) error {
+	type route struct {
+		client  eventClient
+		events  []*v1alpha1.TelemetryEvent
+		indices []int
+	}
+
+	var parts []*route
+	byClient := make(map[eventClient]*route)
+	for i, event := range events {
+		client := r.client(event.Kind)
+
+		part, ok := byClient[client]
+		if !ok {
+			part = &route{client: client}
+			byClient[client] = part
+			parts = append(parts, part)
+		}
+
+		part.events = append(part.events, event)
+		part.indices = append(part.indices, i)
+	}
+
+	if len(parts) == 1 {
+		return parts[0].client.ReportEvents(ctx, parts[0].events)
+	}
+
+	errs := make([]error, len(parts))
+
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			errs[i] = part.client.ReportEvents(ctx, part.events)
+		}()
+	}
+	wg.Wait()
+
+	partialErr := &partialFailureError{
+		rejected: make(map[int]string),
+		failed:   make(map[int]error),
+	}
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+
+		var rejectedErr *v1alpha1.RejectedEventsError
+		if errors.As(err, &rejectedErr) {
+			rangeErr := validateRejected(rejectedErr, len(parts[i].indices))
+			if rangeErr == nil {
+				partialErr.errs = append(partialErr.errs, err)
+
+				for j, reason := range rejectedErr.Rejected {
+					partialErr.rejected[parts[i].indices[j]] = reason
+				}
+				continue
+			}
+
+			// Out of range indices fail the whole part, as with an invalid
+			// batch response.
+			err = rangeErr
+		}
+
+		partialErr.errs = append(partialErr.errs, err)
+
+		for _, j := range parts[i].indices {
+			partialErr.failed[j] = err
+		}
+	}
+
+	switch {
+	case len(partialErr.errs) == 0:
+		return nil
+	case len(partialErr.failed) == 0:
+		return &v1alpha1.RejectedEventsError{Rejected: partialErr.rejected}
+	default:
+		return partialErr
+	}
+}
+
+// validateRejected checks that every rejected event index is within a batch
+// of n events.
+func validateRejected(rejectedErr *v1alpha1.RejectedEventsError, n int) error {
+	for i := range rejectedErr.Rejected {
+		if i < 0 || i >= n {
+			return fmt.Errorf("rejected event index out of range: %d", i)
+		}
+	}
+
+	return nil
+}
+
+// partialFailureError is returned when part of a batch failed to send, and
+// other parts were sent (or rejected by the server).
+type partialFailureError struct {
+	// The events rejected by the server, by index, with the reason.
+	rejected map[int]string
+	// The events that failed to send, by index, with the error.
+	failed map[int]error
+	// Every error returned while sending the batch.
+	errs []error
+}
+
+func (e *partialFailureError) Error() string {
+	return errors.Join(e.errs...).Error()
+}
+
+func (e *partialFailureError) Unwrap() []error {
+	return e.errs
+}
+
+// batchFailures returns the events in a batch rejected by the server (by
+// index, with the reason), and a function returning the error each of the
+// remaining events failed to send with, if any, given the error the batch
+// failed to send with.
+func batchFailures(err error) (rejected map[int]string, failed func(i int) error) {
+	var partialErr *partialFailureError
+	if errors.As(err, &partialErr) {
+		return partialErr.rejected, func(i int) error {
+			return partialErr.failed[i]
+		}
+	}
+
+	var rejectedErr *v1alpha1.RejectedEventsError
+	if errors.As(err, &rejectedErr) {
+		return rejectedErr.Rejected, func(int) error {
+			return nil
+		}
+	}
+
+	return nil, func(int) error {
+		return err
+	}
+}
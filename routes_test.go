@@ -0,0 +1,202 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_KindRoutes(t *testing.T) {
+	t.Run("Events", func(t *testing.T) {
+		// Start mock analytics and incident servers.
+		analyticsServer, analyticsEventCh := mockTelemetryServer(t)
+		t.Cleanup(analyticsServer.Close)
+
+		incidentServer, incidentEventCh := mockTelemetryServer(t)
+		t.Cleanup(incidentServer.Close)
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL: analyticsServer.URL,
+			KindRoutes: map[v1alpha1.TelemetryEventKind]string{
+				v1alpha1.TelemetryEventKindError: incidentServer.URL,
+			},
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportError("ErrorEvent", nil, nil)
+
+		event := receiveEvent(t, incidentEventCh)
+		assert.Equal(t, "ErrorEvent", event.Name)
+
+		reporter.ReportInfo("InfoEvent", nil)
+
+		event = receiveEvent(t, analyticsEventCh)
+		assert.Equal(t, "InfoEvent", event.Name)
+
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		assert.Empty(t, analyticsEventCh)
+		assert.Empty(t, incidentEventCh)
+	})
+
+	t.Run("Batches", func(t *testing.T) {
+		// Start mock analytics and incident servers.
+		analyticsServer, analyticsBatchCh := mockBatchTelemetryServer(t, nil)
+		t.Cleanup(analyticsServer.Close)
+
+		incidentServer, incidentBatchCh := mockBatchTelemetryServer(t, nil)
+		t.Cleanup(incidentServer.Close)
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL: analyticsServer.URL,
+			KindRoutes: map[v1alpha1.TelemetryEventKind]string{
+				v1alpha1.TelemetryEventKindError: incidentServer.URL,
+			},
+			BatchSize:     4,
+			BatchInterval: time.Hour,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportInfo("InfoEvent1", nil)
+		reporter.ReportError("ErrorEvent", nil, nil)
+		reporter.ReportWarning("WarningEvent", "", nil)
+		reporter.ReportInfo("InfoEvent2", nil)
+
+		// The batch is split between the servers, preserving order.
+		batch := receiveBatch(t, analyticsBatchCh)
+		assert.Equal(t, []string{"InfoEvent1", "WarningEvent", "InfoEvent2"}, eventNames(batch))
+
+		batch = receiveBatch(t, incidentBatchCh)
+		assert.Equal(t, []string{"ErrorEvent"}, eventNames(batch))
+
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		stats := reporter.Stats()
+		assert.Equal(t, uint64(4), stats.Reported)
+		assert.Zero(t, stats.Failed)
+	})
+
+	t.Run("Partial Failure", func(t *testing.T) {
+		// Start a mock analytics server, and an incident server that always
+		// fails.
+		analyticsServer, analyticsBatchCh := mockBatchTelemetryServer(t, nil)
+		t.Cleanup(analyticsServer.Close)
+
+		incidentServer, incidentBatchCh := mockBatchTelemetryServer(t, func(w http.ResponseWriter, _ []*v1alpha1.TelemetryEvent) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+		t.Cleanup(incidentServer.Close)
+
+		var dropped atomic.Int32
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL: analyticsServer.URL,
+			KindRoutes: map[v1alpha1.TelemetryEventKind]string{
+				v1alpha1.TelemetryEventKindError: incidentServer.URL,
+			},
+			BatchSize:     3,
+			BatchInterval: time.Hour,
+			OnDrop: func(*v1alpha1.TelemetryEvent, telemetry.DropReason) {
+				dropped.Add(1)
+			},
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportInfo("InfoEvent", nil)
+		reporter.ReportError("ErrorEvent1", nil, nil)
+		reporter.ReportError("ErrorEvent2", nil, nil)
+
+		batch := receiveBatch(t, analyticsBatchCh)
+		assert.Equal(t, []string{"InfoEvent"}, eventNames(batch))
+
+		batch = receiveBatch(t, incidentBatchCh)
+		assert.Equal(t, []string{"ErrorEvent1", "ErrorEvent2"}, eventNames(batch))
+
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		// The failed events are counted as failures, not server rejections.
+		stats := reporter.Stats()
+		assert.Equal(t, uint64(1), stats.Reported)
+		assert.Equal(t, uint64(2), stats.Failed)
+		assert.Equal(t, uint64(2), stats.Failures[telemetry.FailureReasonServerError])
+		assert.Zero(t, dropped.Load())
+	})
+
+	t.Run("Rejected Out Of Range", func(t *testing.T) {
+		// Start mock analytics and incident servers.
+		analyticsServer, analyticsBatchCh := mockBatchTelemetryServer(t, nil)
+		t.Cleanup(analyticsServer.Close)
+
+		incidentServer, incidentBatchCh := mockBatchTelemetryServer(t, nil)
+		t.Cleanup(incidentServer.Close)
+
+		// Create a new telemetry reporter, that claims an event beyond the
+		// end of each batch was rejected.
+		conf := telemetry.Configuration{
+			BaseURL: analyticsServer.URL,
+			KindRoutes: map[v1alpha1.TelemetryEventKind]string{
+				v1alpha1.TelemetryEventKindError: incidentServer.URL,
+			},
+			BatchSize:     2,
+			BatchInterval: time.Hour,
+			ValidateBatchResponse: func(*http.Response, []*v1alpha1.TelemetryEvent) error {
+				return &v1alpha1.RejectedEventsError{Rejected: map[int]string{5: "invalid event"}}
+			},
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportInfo("InfoEvent", nil)
+		reporter.ReportError("ErrorEvent", nil, nil)
+
+		receiveBatch(t, analyticsBatchCh)
+		receiveBatch(t, incidentBatchCh)
+
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		// The invalid response fails the send, rather than crashing.
+		stats := reporter.Stats()
+		assert.Zero(t, stats.Reported)
+		assert.Equal(t, uint64(2), stats.Failed)
+		assert.Zero(t, stats.Dropped[telemetry.DropReasonRejected])
+	})
+
+	t.Run("Invalid Route", func(t *testing.T) {
+		conf := telemetry.Configuration{
+			BaseURL: "https://telemetry.example.com",
+			KindRoutes: map[v1alpha1.TelemetryEventKind]string{
+				v1alpha1.TelemetryEventKindError: "ftp://incidents.example.com",
+			},
+		}
+
+		_, err := telemetry.NewReporterWithError(context.Background(), slog.Default(), conf)
+		require.Error(t, err)
+	})
+}
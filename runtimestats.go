@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"runtime"
+	"slices"
+	"strconv"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// The value keys of the runtime stats attached to events.
+const (
+	goroutinesValueKey = "runtime_goroutines"
+	heapAllocValueKey  = "runtime_heap_alloc_bytes"
+	numGCValueKey      = "runtime_num_gc"
+)
+
+// The event kinds runtime stats are attached to by default.
+var defaultRuntimeStatsKinds = []v1alpha1.TelemetryEventKind{
+	v1alpha1.TelemetryEventKindError,
+	v1alpha1.TelemetryEventKindWarning,
+}
+
+// runtimeStats attaches a snapshot of the Go runtime stats to events.
+type runtimeStats struct {
+	kinds []v1alpha1.TelemetryEventKind
+}
+
+// newRuntimeStats returns an enricher that attaches runtime stats to events
+// of the given kinds, or nil if runtime stats are disabled (a nil enricher
+// does nothing).
+func newRuntimeStats(enabled bool, kinds []v1alpha1.TelemetryEventKind) *runtimeStats {
+	if !enabled {
+		return nil
+	}
+
+	if len(kinds) == 0 {
+		kinds = defaultRuntimeStatsKinds
+	}
+
+	return &runtimeStats{kinds: kinds}
+}
+
+// enrich adds the current goroutine count, heap allocation, and number of
+// completed GC cycles to the event's values, if it is of a matching kind.
+func (s *runtimeStats) enrich(event *v1alpha1.TelemetryEvent) {
+	if s == nil || !slices.Contains(s.kinds, event.Kind) {
+		return
+	}
+
+	// Briefly stops the world, hence it is limited to selected kinds.
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	setValue(event, goroutinesValueKey, strconv.Itoa(runtime.NumGoroutine()))
+	setValue(event, heapAllocValueKey, strconv.FormatUint(memStats.HeapAlloc, 10))
+	setValue(event, numGCValueKey, strconv.FormatUint(uint64(memStats.NumGC), 10))
+}
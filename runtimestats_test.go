@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_RuntimeStats(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	runtimeKeys := []string{"runtime_goroutines", "runtime_heap_alloc_bytes", "runtime_num_gc"}
+
+	ctx := context.Background()
+
+	t.Run("Default", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL:      server.URL,
+			RuntimeStats: true,
+		})
+
+		reporter.ReportError("TestError", errors.New("test"), nil)
+
+		event := receiveEvent(t, eventCh)
+		for _, key := range runtimeKeys {
+			require.Contains(t, event.Values, key)
+
+			_, err := strconv.ParseUint(event.Values[key], 10, 64)
+			assert.NoError(t, err, key)
+		}
+		assert.NotEqual(t, "0", event.Values["runtime_goroutines"])
+
+		// Informational events are not enriched by default.
+		reporter.ReportInfo("TestInfo", nil)
+
+		event = receiveEvent(t, eventCh)
+		for _, key := range runtimeKeys {
+			assert.NotContains(t, event.Values, key)
+		}
+
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+
+	t.Run("Kinds", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL:           server.URL,
+			RuntimeStats:      true,
+			RuntimeStatsKinds: []v1alpha1.TelemetryEventKind{v1alpha1.TelemetryEventKindInfo},
+		})
+
+		reporter.ReportInfo("TestInfo", nil)
+
+		event := receiveEvent(t, eventCh)
+		for _, key := range runtimeKeys {
+			assert.Contains(t, event.Values, key)
+		}
+
+		reporter.ReportError("TestError", errors.New("test"), nil)
+
+		event = receiveEvent(t, eventCh)
+		for _, key := range runtimeKeys {
+			assert.NotContains(t, event.Values, key)
+		}
+
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: server.URL,
+		})
+
+		reporter.ReportError("TestError", errors.New("test"), nil)
+
+		event := receiveEvent(t, eventCh)
+		for _, key := range runtimeKeys {
+			assert.NotContains(t, event.Values, key)
+		}
+
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// sampler decides which events are kept based on the configured sample rates.
+type sampler struct {
+	rate     float64
+	tagRates map[string]float64
+	// rand returns a pseudo-random number in the half-open interval [0.0, 1.0).
+	rand func() float64
+}
+
+func newSampler(rate float64, tagRates map[string]float64) *sampler {
+	// A zero rate means sampling is not configured, so keep everything.
+	if rate == 0 {
+		rate = 1
+	}
+
+	return &sampler{
+		rate:     rate,
+		tagRates: tagRates,
+		rand:     rand.Float64,
+	}
+}
+
+// sample returns true if an event with the given tags should be kept.
+func (s *sampler) sample(tags []string) bool {
+	rate := s.rate
+
+	// If the event carries one or more tags with a configured sample rate,
+	// the most permissive of those rates takes precedence over the global rate.
+	matched := false
+	for _, tag := range tags {
+		tagRate, ok := s.tagRates[tag]
+		if !ok {
+			continue
+		}
+
+		if !matched || tagRate > rate {
+			rate = tagRate
+			matched = true
+		}
+	}
+
+	if rate >= 1 {
+		return true
+	} else if rate <= 0 {
+		return false
+	}
+
+	return s.rand() < rate
+}
+
+// The time constant over which the adaptive sampler averages the event rate.
+const adaptiveSampleWindow = 10 * time.Second
+
+// adaptiveSampler keeps events at a rate of at most target events per second,
+// by dropping events with a probability that increases with the observed
+// event rate. The observed rate is an exponentially decaying average, so the
+// sampler relaxes again once traffic quietens.
+type adaptiveSampler struct {
+	target float64
+	mu     sync.Mutex
+	// The average rate of reported events (before sampling), in events per
+	// second.
+	rate float64
+	last time.Time
+	// rand returns a pseudo-random number in the half-open interval [0.0, 1.0).
+	rand func() float64
+}
+
+func newAdaptiveSampler(target float64) *adaptiveSampler {
+	return &adaptiveSampler{
+		target: target,
+		rand:   rand.Float64,
+	}
+}
+
+// sample returns true if an event of the given kind, reported at now, should
+// be kept. Error events are always kept.
+func (s *adaptiveSampler) sample(kind v1alpha1.TelemetryEventKind, now time.Time) bool {
+	if s.target <= 0 {
+		return true
+	}
+
+	s.mu.Lock()
+	if !s.last.IsZero() {
+		// A clock going backwards is treated as no time having passed.
+		d, _ := elapsed(s.last, now)
+		s.rate *= math.Exp(-d.Seconds() / adaptiveSampleWindow.Seconds())
+	}
+	s.rate += 1 / adaptiveSampleWindow.Seconds()
+	s.last = now
+	rate := s.rate
+	s.mu.Unlock()
+
+	if kind == v1alpha1.TelemetryEventKindError || rate <= s.target {
+		return true
+	}
+
+	return s.rand() < s.target/rate
+}
@@ -0,0 +1,115 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampler(t *testing.T) {
+	s := newSampler(0.01, map[string]float64{
+		"critical": 1,
+		"debug":    0,
+		"sampled":  0.5,
+	})
+	s.rand = rand.New(rand.NewPCG(1, 2)).Float64
+
+	const n = 100000
+
+	count := func(tags ...string) int {
+		var kept int
+		for i := 0; i < n; i++ {
+			if s.sample(tags) {
+				kept++
+			}
+		}
+		return kept
+	}
+
+	t.Run("Untagged", func(t *testing.T) {
+		assert.InDelta(t, 0.01, float64(count())/n, 0.002)
+	})
+
+	t.Run("Unmatched Tag", func(t *testing.T) {
+		assert.InDelta(t, 0.01, float64(count("other"))/n, 0.002)
+	})
+
+	t.Run("Critical", func(t *testing.T) {
+		assert.Equal(t, n, count("critical"))
+	})
+
+	t.Run("Debug", func(t *testing.T) {
+		assert.Zero(t, count("debug"))
+	})
+
+	t.Run("Sampled", func(t *testing.T) {
+		assert.InDelta(t, 0.5, float64(count("sampled"))/n, 0.01)
+	})
+
+	t.Run("Most Permissive Tag Wins", func(t *testing.T) {
+		assert.Equal(t, n, count("debug", "critical"))
+	})
+}
+
+func TestSampler_Default(t *testing.T) {
+	s := newSampler(0, nil)
+	s.rand = func() float64 {
+		t.Fatal("rand should not be consulted when sampling is disabled")
+		return 0
+	}
+
+	assert.True(t, s.sample(nil))
+	assert.True(t, s.sample([]string{"critical"}))
+}
+
+func TestAdaptiveSampler(t *testing.T) {
+	const target = 10
+
+	s := newAdaptiveSampler(target)
+	s.rand = rand.New(rand.NewPCG(1, 2)).Float64
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// feed reports events of the given kind at a steady rate, returning the
+	// rate of kept events over the final ten seconds.
+	feed := func(kind v1alpha1.TelemetryEventKind, perSecond int, d time.Duration) float64 {
+		interval := time.Second / time.Duration(perSecond)
+		measureFrom := now.Add(d - 10*time.Second)
+
+		var kept int
+		for end := now.Add(d); now.Before(end); now = now.Add(interval) {
+			if s.sample(kind, now) && !now.Before(measureFrom) {
+				kept++
+			}
+		}
+
+		return float64(kept) / 10
+	}
+
+	t.Run("Burst", func(t *testing.T) {
+		rate := feed(v1alpha1.TelemetryEventKindInfo, 1000, time.Minute)
+		assert.InEpsilon(t, target, rate, 0.2)
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		rate := feed(v1alpha1.TelemetryEventKindError, 1000, 20*time.Second)
+		assert.Equal(t, float64(1000), rate)
+	})
+
+	t.Run("Trickle", func(t *testing.T) {
+		rate := feed(v1alpha1.TelemetryEventKindInfo, 2, 2*time.Minute)
+		assert.Equal(t, float64(2), rate)
+	})
+}
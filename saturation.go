@@ -0,0 +1,105 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// SaturatedEventName is the name given to the warning event reported when the
+// reporter drops more events than SaturationThreshold because its queue is
+// full.
+const SaturatedEventName = "telemetry_saturated"
+
+const (
+	// The default window over which queue full drops are counted.
+	defaultSaturationWindow = time.Minute
+	// The value key holding the number of events dropped within the window.
+	droppedValueKey = "dropped"
+)
+
+// saturation detects when the reporter is saturated, that is it drops at
+// least threshold events within a window because its queue is full. A nil
+// saturation does nothing.
+type saturation struct {
+	threshold int
+	window    time.Duration
+	clock     Clock
+	report    func(*v1alpha1.TelemetryEvent)
+	mu        sync.Mutex
+	// The start of the current window.
+	start time.Time
+	// The number of events dropped in the current window.
+	dropped int
+	// Whether saturation has already been reported in the current window.
+	reported bool
+}
+
+func newSaturation(threshold int, window time.Duration, clock Clock, report func(*v1alpha1.TelemetryEvent)) *saturation {
+	if threshold <= 0 {
+		return nil
+	}
+
+	if window <= 0 {
+		window = defaultSaturationWindow
+	}
+
+	return &saturation{
+		threshold: threshold,
+		window:    window,
+		clock:     clock,
+		report:    report,
+	}
+}
+
+// record records that an event was dropped because the queue was full,
+// reporting saturation (at most once per window) if the threshold is
+// crossed. The saturation event itself is never counted, to avoid a feedback
+// loop.
+func (s *saturation) record(event *v1alpha1.TelemetryEvent) {
+	if s == nil || (event != nil && event.Name == SaturatedEventName) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.clock.Now()
+	if d, ok := elapsed(s.start, now); s.start.IsZero() || !ok || d >= s.window {
+		s.start = now
+		s.dropped = 0
+		s.reported = false
+	}
+
+	s.dropped++
+	if s.dropped < s.threshold || s.reported {
+		return
+	}
+
+	s.reported = true
+
+	event = &v1alpha1.TelemetryEvent{
+		Kind:     v1alpha1.TelemetryEventKindWarning,
+		Priority: v1alpha1.TelemetryEventPriorityHigh,
+		Name:     SaturatedEventName,
+		Message:  "Telemetry queue is saturated, events are being dropped",
+		Values: map[string]string{
+			droppedValueKey: strconv.Itoa(s.dropped),
+		},
+	}
+
+	// Reported asynchronously, as events are dropped while locks are held
+	// (eg. by the batcher).
+	go s.report(event)
+}
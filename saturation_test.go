@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Saturation(t *testing.T) {
+	// Start a mock telemetry server that holds requests until released.
+	release := make(chan struct{})
+
+	var mu sync.Mutex
+	var saturated []*v1alpha1.TelemetryEvent
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+
+		var event v1alpha1.TelemetryEvent
+		if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		if event.Name == telemetry.SaturatedEventName {
+			mu.Lock()
+			saturated = append(saturated, &event)
+			mu.Unlock()
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	teeCh := make(chan *v1alpha1.TelemetryEvent, 1024)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:             server.URL,
+		SaturationThreshold: 5,
+		SaturationWindow:    time.Minute,
+		Clock:               clock,
+		Tee:                 teeCh,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	flood := func() {
+		for i := 0; i < telemetry.MaxConcurrentReports+20; i++ {
+			reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+				Name: "TestEvent",
+			})
+		}
+	}
+
+	// waitSaturated waits for the saturation event to be accepted for
+	// sending.
+	waitSaturated := func(t *testing.T) *v1alpha1.TelemetryEvent {
+		t.Helper()
+
+		timeout := time.After(time.Second)
+		for {
+			select {
+			case event := <-teeCh:
+				if event.Name == telemetry.SaturatedEventName {
+					return event
+				}
+			case <-timeout:
+				t.Fatal("Timeout waiting for saturation event")
+				return nil
+			}
+		}
+	}
+
+	// Flood the reporter, twice over, within a single window.
+	flood()
+	flood()
+
+	event := waitSaturated(t)
+	assert.Equal(t, v1alpha1.TelemetryEventKindWarning, event.Kind)
+	assert.True(t, event.HighPriority())
+	assert.Equal(t, "5", event.Values["dropped"])
+
+	// Saturation is reported again in a later window.
+	clock.Advance(time.Minute)
+	flood()
+
+	waitSaturated(t)
+
+	close(release)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	mu.Lock()
+	assert.Len(t, saturated, 2)
+	mu.Unlock()
+
+	// Only one saturation event is reported per window.
+	for len(teeCh) > 0 {
+		event := <-teeCh
+		assert.NotEqual(t, telemetry.SaturatedEventName, event.Name)
+	}
+
+	assert.GreaterOrEqual(t, reporter.Stats().Dropped[telemetry.DropReasonQueueFull], uint64(60))
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import "sync/atomic"
+
+// session holds the current session ID, which may be rotated concurrently
+// with events being reported.
+type session struct {
+	generateID func() string
+	id         atomic.Pointer[string]
+}
+
+func newSession(generateID func() string) *session {
+	s := &session{
+		generateID: generateID,
+	}
+	s.rotate()
+
+	return s
+}
+
+func (s *session) current() string {
+	return *s.id.Load()
+}
+
+func (s *session) rotate() {
+	id := s.generateID()
+	s.id.Store(&id)
+}
+
+// SessionID returns the current session ID, it is attached to every event
+// that doesn't set its own.
+func (r *Reporter) SessionID() string {
+	return r.session.current()
+}
+
+// RotateSession starts a new session (eg. on a user logging out), subsequent
+// events will carry a newly generated session ID.
+func (r *Reporter) RotateSession() {
+	r.session.rotate()
+}
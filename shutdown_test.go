@@ -0,0 +1,353 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_ShutdownFlush(t *testing.T) {
+	t.Run("Batch", func(t *testing.T) {
+		// Start a mock telemetry server.
+		server, batchCh := mockBatchTelemetryServer(t, nil)
+		t.Cleanup(server.Close)
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL:       server.URL,
+			BatchSize:     10,
+			BatchInterval: time.Hour,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		for i := 0; i < 3; i++ {
+			reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+				Name: fmt.Sprintf("Event%d", i),
+			})
+		}
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+		t.Cleanup(cancel)
+
+		require.NoError(t, reporter.Shutdown(shutdownCtx))
+
+		// The batch should have been sent before Shutdown returned.
+		select {
+		case batch := <-batchCh:
+			assert.Equal(t, []string{"Event0", "Event1", "Event2"}, eventNames(batch))
+		default:
+			t.Fatal("Expected batch to have been sent")
+		}
+
+		assert.Equal(t, uint64(3), reporter.Stats().Reported)
+	})
+
+	t.Run("Queue", func(t *testing.T) {
+		// Start a mock telemetry server.
+		server, eventCh := mockTelemetryServer(t)
+		t.Cleanup(server.Close)
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL:      server.URL,
+			SendInterval: 10 * time.Millisecond,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		for i := 0; i < 3; i++ {
+			reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+				Name: fmt.Sprintf("Event%d", i),
+			})
+		}
+
+		go func() {
+			for i := 0; i < 3; i++ {
+				receiveEvent(t, eventCh)
+			}
+		}()
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+		t.Cleanup(cancel)
+
+		require.NoError(t, reporter.Shutdown(shutdownCtx))
+
+		stats := reporter.Stats()
+		assert.Equal(t, uint64(3), stats.Reported)
+		assert.Zero(t, stats.TotalDropped())
+	})
+}
+
+func TestReporter_ShutdownTimeout(t *testing.T) {
+	t.Run("Batch", func(t *testing.T) {
+		// Start a mock telemetry server that never responds.
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			<-r.Context().Done()
+		}))
+		t.Cleanup(server.Close)
+
+		// Create a new telemetry reporter.
+		conf := telemetry.Configuration{
+			BaseURL:       server.URL,
+			BatchSize:     10,
+			BatchInterval: time.Hour,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		t.Cleanup(cancel)
+
+		start := time.Now()
+		require.NoError(t, reporter.Shutdown(shutdownCtx))
+		assert.Less(t, time.Since(start), time.Second)
+
+		assert.Equal(t, uint64(1), reporter.Stats().Failed)
+	})
+
+	t.Run("Queue", func(t *testing.T) {
+		// Start a mock telemetry server.
+		server, eventCh := mockTelemetryServer(t)
+		t.Cleanup(server.Close)
+
+		// Create a new telemetry reporter that only sends an event an hour.
+		conf := telemetry.Configuration{
+			BaseURL:      server.URL,
+			SendInterval: time.Hour,
+		}
+
+		ctx := context.Background()
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		for i := 0; i < 3; i++ {
+			reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+				Name: fmt.Sprintf("Event%d", i),
+			})
+		}
+
+		// Only the first event is sent immediately.
+		receiveEvent(t, eventCh)
+
+		shutdownCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		t.Cleanup(cancel)
+
+		start := time.Now()
+		require.NoError(t, reporter.Shutdown(shutdownCtx))
+		assert.Less(t, time.Since(start), time.Second)
+
+		stats := reporter.Stats()
+		assert.Equal(t, uint64(1), stats.Reported)
+		assert.Equal(t, uint64(2), stats.Dropped[telemetry.DropReasonShuttingDown])
+	})
+}
+
+func TestReporter_ShutdownIdempotent(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		BatchSize:     10,
+		BatchInterval: time.Hour,
+	}
+
+	ctx := context.Background()
+
+	t.Run("ShutdownTwice", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		require.NoError(t, reporter.Shutdown(ctx))
+		require.NoError(t, reporter.Shutdown(ctx))
+		require.NoError(t, reporter.Close())
+	})
+
+	t.Run("CloseThenShutdown", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+
+		require.NoError(t, reporter.Close())
+		require.NoError(t, reporter.Close())
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		// The buffered event was dropped by Close, and not flushed by Shutdown.
+		assert.Equal(t, uint64(1), reporter.Stats().Dropped[telemetry.DropReasonShuttingDown])
+
+		select {
+		case event := <-eventCh:
+			t.Fatalf("Expected no telemetry event, but got: %v", event)
+		default:
+		}
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		errCh := make(chan error, 8)
+		for i := 0; i < cap(errCh); i++ {
+			go func() {
+				if i%2 == 0 {
+					errCh <- reporter.Shutdown(ctx)
+				} else {
+					errCh <- reporter.Close()
+				}
+			}()
+		}
+
+		for i := 0; i < cap(errCh); i++ {
+			require.NoError(t, <-errCh)
+		}
+	})
+}
+
+func TestReporter_CloseWithin(t *testing.T) {
+	receivedCh := make(chan string, 2)
+
+	// Start a mock telemetry server that responds quickly to some events, and
+	// never responds to others.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event v1alpha1.TelemetryEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		receivedCh <- event.Name
+
+		if event.Name == "Slow" {
+			<-r.Context().Done()
+			return
+		}
+
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "Fast"})
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{Name: "Slow"})
+
+	// Wait for both reports to be in-flight.
+	var received []string
+	for i := 0; i < 2; i++ {
+		select {
+		case name := <-receivedCh:
+			received = append(received, name)
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for telemetry events")
+		}
+	}
+	assert.ElementsMatch(t, []string{"Fast", "Slow"}, received)
+
+	closeCtx, cancel := context.WithTimeout(ctx, 250*time.Millisecond)
+	t.Cleanup(cancel)
+
+	start := time.Now()
+	require.NoError(t, reporter.CloseWithin(closeCtx))
+	assert.Less(t, time.Since(start), time.Second)
+
+	// The fast report finished, while the slow one was cancelled.
+	stats := reporter.Stats()
+	assert.Equal(t, uint64(1), stats.Reported)
+	assert.Equal(t, uint64(1), stats.Failed)
+
+	// New events are rejected.
+	require.ErrorIs(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{Name: "Late"}), telemetry.ErrShuttingDown)
+
+	require.NoError(t, reporter.CloseWithin(ctx))
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_ContextCancelled(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	var shuttingDown atomic.Int32
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		OnDrop: func(event *v1alpha1.TelemetryEvent, reason telemetry.DropReason) {
+			if reason == telemetry.DropReasonShuttingDown {
+				shuttingDown.Add(1)
+			}
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	require.NoError(t, reporter.ReportEventSync(context.Background(), &v1alpha1.TelemetryEvent{
+		Name: "BeforeCancel",
+	}))
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "BeforeCancel", event.Name)
+
+	cancel()
+
+	// The reporter is closed asynchronously once the context is cancelled.
+	require.Eventually(t, func() bool {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "AfterCancel",
+		})
+
+		return shuttingDown.Load() > 0
+	}, time.Second, 10*time.Millisecond)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "AfterCancel",
+	})
+
+	assert.Greater(t, shuttingDown.Load(), int32(1))
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no telemetry event, but got: %v", event)
+	default:
+	}
+
+	// Shutting down a closed reporter does nothing.
+	require.NoError(t, reporter.Shutdown(context.Background()))
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Overridden in tests.
+var (
+	notifySignals = signal.Notify
+	stopSignals   = signal.Stop
+)
+
+// InstallSignalHandler shuts down the reporter, allowing up to timeout for
+// pending events to be sent, when the process receives SIGINT or SIGTERM.
+// Any handlers the application has registered with signal.Notify still
+// receive the signal, and next (if not nil) is called with the signal once
+// the reporter has shut down, eg. to exit the process. If next is nil, exiting
+// is left to the application's own handlers (pass ExitOnSignal to terminate
+// the process instead). The returned function uninstalls the handler.
+func InstallSignalHandler(r *Reporter, timeout time.Duration, next func(os.Signal)) (stop func()) {
+	sigCh := make(chan os.Signal, 1)
+	notifySignals(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-done:
+			return
+		case sig := <-sigCh:
+			stopSignals(sigCh)
+
+			r.logger.Debug("Received signal, shutting down telemetry reporter", slog.String("signal", sig.String()))
+
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+
+			if err := r.Shutdown(ctx); err != nil {
+				r.logger.Warn("Failed to shut down telemetry reporter", slog.Any("error", err))
+			}
+
+			if next != nil {
+				next(sig)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stopSignals(sigCh)
+			close(done)
+		})
+	}
+}
+
+// ExitOnSignal restores the default action of sig (as signal.Notify disables
+// it) and raises it again, so the process terminates as if it had never been
+// caught. It can be passed to InstallSignalHandler by applications that don't
+// handle signals themselves. Restoring the default action also removes every
+// other signal.Notify registration for sig.
+func ExitOnSignal(sig os.Signal) {
+	signal.Reset(sig)
+
+	p, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = p.Signal(sig)
+	}
+
+	if err != nil {
+		// The signal can't be raised on this platform, so exit instead.
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstallSignalHandler(t *testing.T) {
+	// Capture the channel the handler is notified on.
+	notifyCh := make(chan chan<- os.Signal, 1)
+	stoppedCh := make(chan struct{}, 2)
+	t.Cleanup(telemetry.SetNotifySignals(func(c chan<- os.Signal, sig ...os.Signal) {
+		assert.ElementsMatch(t, []os.Signal{os.Interrupt, syscall.SIGTERM}, sig)
+		notifyCh <- c
+	}, func(c chan<- os.Signal) {
+		stoppedCh <- struct{}{}
+	}))
+
+	ctx := context.Background()
+
+	t.Run("Signal", func(t *testing.T) {
+		// Start a mock telemetry server.
+		server, eventCh := mockTelemetryServer(t)
+		t.Cleanup(server.Close)
+
+		conf := telemetry.Configuration{
+			BaseURL: server.URL,
+		}
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		nextCh := make(chan os.Signal, 1)
+		stop := telemetry.InstallSignalHandler(reporter, time.Second, func(sig os.Signal) {
+			nextCh <- sig
+		})
+		t.Cleanup(stop)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+
+		sigCh := <-notifyCh
+		sigCh <- syscall.SIGTERM
+
+		// The chained handler is called once the reporter has shut down.
+		select {
+		case sig := <-nextCh:
+			assert.Equal(t, syscall.SIGTERM, sig)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Timeout waiting for the chained signal handler")
+		}
+
+		require.ErrorIs(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		}), telemetry.ErrShuttingDown)
+
+		// The pending event was sent before shutting down.
+		assert.Equal(t, uint64(1), reporter.Stats().Reported)
+		assert.Equal(t, "TestEvent", receiveEvent(t, eventCh).Name)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		// Start a mock telemetry server that counts requests.
+		var hits atomic.Int64
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			hits.Add(1)
+			w.WriteHeader(http.StatusOK)
+		}))
+		t.Cleanup(server.Close)
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: server.URL,
+		})
+
+		stop := telemetry.InstallSignalHandler(reporter, time.Second, nil)
+		t.Cleanup(stop)
+
+		// Drain the stops from the previous subtest.
+		for len(stoppedCh) > 0 {
+			<-stoppedCh
+		}
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+
+		sigCh := <-notifyCh
+		sigCh <- os.Interrupt
+
+		// Without a chained handler, the reporter is shut down and exiting is
+		// left to the application (the signal is not raised again).
+		require.Eventually(t, func() bool {
+			return errors.Is(reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+				Name: "TestEvent",
+			}), telemetry.ErrShuttingDown)
+		}, 5*time.Second, 10*time.Millisecond)
+
+		// The pending event was sent before shutting down.
+		assert.NotZero(t, hits.Load())
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			BaseURL: "http://telemetry.invalid",
+		})
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Close())
+		})
+
+		stop := telemetry.InstallSignalHandler(reporter, time.Second, nil)
+		<-notifyCh
+
+		// Drain the stop from the previous subtest.
+		for len(stoppedCh) > 0 {
+			<-stoppedCh
+		}
+
+		stop()
+		stop()
+
+		assert.Len(t, stoppedCh, 1)
+
+		// The reporter is still accepting events.
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+		assert.Zero(t, reporter.Stats().Dropped[telemetry.DropReasonShuttingDown])
+	})
+}
@@ -0,0 +1,178 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// LogEventName is the name given to telemetry events created from log records.
+const LogEventName = "log"
+
+// HandlerOptions are options for a Handler.
+type HandlerOptions struct {
+	// Level is the minimum level of log records that will be reported as
+	// telemetry events. Defaults to slog.LevelWarn.
+	Level slog.Leveler
+	// LevelToKind is an optional function that maps the level of a log record
+	// to the kind of the reported event. Defaults to DefaultLevelToKind.
+	LevelToKind func(slog.Level) v1alpha1.TelemetryEventKind
+}
+
+// Handler is a slog.Handler that reports log records as telemetry events,
+// and passes them through to an underlying handler for normal logging.
+type Handler struct {
+	next     slog.Handler
+	reporter *Reporter
+	level    slog.Leveler
+	toKind   func(slog.Level) v1alpha1.TelemetryEventKind
+	values   map[string]string
+	prefix   string
+}
+
+// NewHandler creates a new slog.Handler that reports log records at or above
+// the configured level using the given reporter. Every record is also passed
+// through to next (if not nil). The reporter must not itself log through the
+// returned handler, otherwise its failures may be reported in a loop.
+func NewHandler(next slog.Handler, reporter *Reporter, opts *HandlerOptions) *Handler {
+	var level slog.Leveler = slog.LevelWarn
+	if opts != nil && opts.Level != nil {
+		level = opts.Level
+	}
+
+	toKind := DefaultLevelToKind
+	if opts != nil && opts.LevelToKind != nil {
+		toKind = opts.LevelToKind
+	}
+
+	return &Handler{
+		next:     next,
+		reporter: reporter,
+		level:    level,
+		toKind:   toKind,
+	}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	if level >= h.level.Level() {
+		return true
+	}
+
+	return h.next != nil && h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= h.level.Level() {
+		values := make(map[string]string, len(h.values)+record.NumAttrs())
+		for k, v := range h.values {
+			values[k] = v
+		}
+
+		record.Attrs(func(attr slog.Attr) bool {
+			addAttr(values, h.prefix, attr)
+			return true
+		})
+
+		event := &v1alpha1.TelemetryEvent{
+			Kind:    h.toKind(record.Level),
+			Name:    LogEventName,
+			Message: record.Message,
+		}
+		if len(values) > 0 {
+			event.Values = values
+		}
+
+		h.reporter.ReportEventCtx(ctx, event)
+	}
+
+	if h.next != nil && h.next.Enabled(ctx, record.Level) {
+		return h.next.Handle(ctx, record)
+	}
+
+	return nil
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := h.clone()
+
+	h2.values = make(map[string]string, len(h.values)+len(attrs))
+	for k, v := range h.values {
+		h2.values[k] = v
+	}
+
+	for _, attr := range attrs {
+		addAttr(h2.values, h.prefix, attr)
+	}
+
+	if h.next != nil {
+		h2.next = h.next.WithAttrs(attrs)
+	}
+
+	return h2
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := h.clone()
+	h2.prefix = h.prefix + name + "."
+
+	if h.next != nil {
+		h2.next = h.next.WithGroup(name)
+	}
+
+	return h2
+}
+
+func (h *Handler) clone() *Handler {
+	h2 := *h
+	return &h2
+}
+
+// addAttr flattens the attribute into values, qualifying keys with the names
+// of any enclosing groups.
+func addAttr(values map[string]string, prefix string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+
+	if attr.Value.Kind() == slog.KindGroup {
+		// Groups with an empty key are inlined.
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+
+		for _, groupAttr := range attr.Value.Group() {
+			addAttr(values, prefix, groupAttr)
+		}
+
+		return
+	}
+
+	values[prefix+attr.Key] = attr.Value.String()
+}
+
+// DefaultLevelToKind maps a log level to the closest telemetry event kind.
+func DefaultLevelToKind(level slog.Level) v1alpha1.TelemetryEventKind {
+	switch {
+	case level >= slog.LevelError:
+		return v1alpha1.TelemetryEventKindError
+	case level >= slog.LevelWarn:
+		return v1alpha1.TelemetryEventKindWarning
+	default:
+		return v1alpha1.TelemetryEventKindInfo
+	}
+}
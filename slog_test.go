@@ -0,0 +1,166 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	next := &recordingHandler{}
+	logger := slog.New(telemetry.NewHandler(next, reporter, nil)).
+		With(slog.String("component", "test"))
+
+	// Below the threshold, should only be logged.
+	logger.Info("Starting up", slog.Int("workers", 4))
+
+	logger.Warn("Disk almost full", slog.Int("percent", 95))
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindWarning, event.Kind)
+	assert.Equal(t, telemetry.LogEventName, event.Name)
+	assert.Equal(t, "Disk almost full", event.Message)
+	assert.Equal(t, map[string]string{
+		"component": "test",
+		"percent":   "95",
+	}, event.Values)
+
+	logger.WithGroup("request").Error("Request failed",
+		slog.String("method", "GET"),
+		slog.Any("error", errors.New("connection reset")))
+
+	event = receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindError, event.Kind)
+	assert.Equal(t, "Request failed", event.Message)
+	assert.Equal(t, map[string]string{
+		"component":      "test",
+		"request.method": "GET",
+		"request.error":  "connection reset",
+	}, event.Values)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no further telemetry events, but got: %v", event)
+	default:
+	}
+
+	// Every record should have been passed through.
+	var messages []string
+	for _, record := range next.Records() {
+		messages = append(messages, record.Message)
+	}
+	assert.Equal(t, []string{"Starting up", "Disk almost full", "Request failed"}, messages)
+}
+
+func TestHandler_Level(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	logger := slog.New(telemetry.NewHandler(nil, reporter, &telemetry.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+
+	logger.Debug("Verbose detail")
+	logger.Info("Starting up")
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindInfo, event.Kind)
+	assert.Equal(t, "Starting up", event.Message)
+	assert.Empty(t, event.Values)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no further telemetry events, but got: %v", event)
+	default:
+	}
+}
+
+func TestHandler_LevelToKind(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// Treat warnings as informational.
+	logger := slog.New(telemetry.NewHandler(nil, reporter, &telemetry.HandlerOptions{
+		LevelToKind: func(level slog.Level) v1alpha1.TelemetryEventKind {
+			if level >= slog.LevelError {
+				return v1alpha1.TelemetryEventKindError
+			}
+
+			return v1alpha1.TelemetryEventKindInfo
+		},
+	}))
+
+	logger.Warn("Disk almost full")
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindInfo, event.Kind)
+	assert.Equal(t, "Disk almost full", event.Message)
+
+	logger.Error("Request failed")
+
+	event = receiveEvent(t, eventCh)
+	assert.Equal(t, v1alpha1.TelemetryEventKindError, event.Kind)
+	assert.Equal(t, "Request failed", event.Message)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestDefaultLevelToKind(t *testing.T) {
+	assert.Equal(t, v1alpha1.TelemetryEventKindInfo, telemetry.DefaultLevelToKind(slog.LevelDebug))
+	assert.Equal(t, v1alpha1.TelemetryEventKindInfo, telemetry.DefaultLevelToKind(slog.LevelInfo))
+	assert.Equal(t, v1alpha1.TelemetryEventKindWarning, telemetry.DefaultLevelToKind(slog.LevelWarn))
+	assert.Equal(t, v1alpha1.TelemetryEventKindError, telemetry.DefaultLevelToKind(slog.LevelError))
+	assert.Equal(t, v1alpha1.TelemetryEventKindError, telemetry.DefaultLevelToKind(slog.LevelError+4))
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"maps"
+	"strconv"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// The value key used to record the duration of a span, in milliseconds.
+const durationValueKey = "duration_ms"
+
+// StartSpan starts timing an operation. Calling the returned function ends
+// the span, reporting a named informational event with the optional values
+// and the elapsed time in milliseconds as the "duration_ms" value. If the clock
+// went backwards during the span, the duration is zero and the event is
+// flagged with the "clock_anomaly" value.
+func (r *Reporter) StartSpan(name string) func(values map[string]string) {
+	start := r.timestamps.clock.Now()
+
+	return func(values map[string]string) {
+		d, ok := elapsed(start, r.timestamps.clock.Now())
+
+		values = maps.Clone(values)
+		if values == nil {
+			values = make(map[string]string)
+		}
+		values[durationValueKey] = strconv.FormatInt(d.Milliseconds(), 10)
+		if !ok {
+			values[clockAnomalyValueKey] = "true"
+		}
+
+		r.ReportEvent(&v1alpha1.TelemetryEvent{
+			Kind:   v1alpha1.TelemetryEventKindInfo,
+			Name:   name,
+			Values: values,
+		})
+	}
+}
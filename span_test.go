@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_StartSpan(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Clock:   clock,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	end := reporter.StartSpan("Operation")
+
+	clock.Advance(1500 * time.Millisecond)
+
+	values := map[string]string{"result": "ok"}
+	end(values)
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "Operation", event.Name)
+	assert.Equal(t, v1alpha1.TelemetryEventKindInfo, event.Kind)
+	assert.Equal(t, map[string]string{
+		"result":      "ok",
+		"duration_ms": "1500",
+	}, event.Values)
+
+	// The caller's values should not be modified.
+	assert.NotContains(t, values, "duration_ms")
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_StartSpanClockGoingBackwards(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := &fakeClock{now: start}
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Clock:   clock,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	end := reporter.StartSpan("Operation")
+
+	// Simulate an NTP step adjustment moving the wall clock backwards.
+	clock.Set(start.Add(-time.Hour))
+
+	end(nil)
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "Operation", event.Name)
+	assert.Equal(t, map[string]string{
+		"duration_ms":   "0",
+		"clock_anomaly": "true",
+	}, event.Values)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
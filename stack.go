@@ -0,0 +1,131 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"cmp"
+	"go/build"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+const (
+	// The default maximum number of stack frames captured by ReportError and
+	// ReportPanic.
+	defaultMaxStackDepth = 32
+	// The function name of the frame marking a truncated stack trace.
+	truncatedFrameFunction = "(truncated)"
+)
+
+// stackTrace returns the stack trace of the calling goroutine, skipping the
+// given number of frames (as per runtime.Callers). At most maxDepth+1 program
+// counters are captured, so that limitStackDepth can tell whether the stack
+// was deeper than maxDepth.
+func stackTrace(skip, maxDepth int) []*v1alpha1.StackFrame {
+	pcs := make([]uintptr, maxDepth+1)
+	n := runtime.Callers(skip, pcs)
+	if n == 0 {
+		return nil
+	}
+
+	var stackTrace []*v1alpha1.StackFrame
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+
+		stackTrace = append(stackTrace, &v1alpha1.StackFrame{
+			File:     frame.File,
+			Function: frame.Function,
+			Line:     int32(frame.Line),
+		})
+
+		if !more {
+			break
+		}
+	}
+
+	return stackTrace
+}
+
+// limitStackDepth keeps the top maxDepth frames of the stack trace, replacing
+// any remaining frames with a single frame marking the truncation.
+func limitStackDepth(stackTrace []*v1alpha1.StackFrame, maxDepth int) []*v1alpha1.StackFrame {
+	if len(stackTrace) <= maxDepth {
+		return stackTrace
+	}
+
+	return append(stackTrace[:maxDepth:maxDepth], &v1alpha1.StackFrame{
+		Function: truncatedFrameFunction,
+	})
+}
+
+// defaultTrimPathPrefixes returns the build machine specific path prefixes
+// that are always trimmed from stack frames, so that eg.
+// /usr/local/go/src/runtime/proc.go becomes runtime/proc.go.
+func defaultTrimPathPrefixes() []string {
+	var prefixes []string
+	if build.Default.GOROOT != "" {
+		prefixes = append(prefixes, filepath.Join(build.Default.GOROOT, "src"))
+	}
+
+	for _, gopath := range filepath.SplitList(build.Default.GOPATH) {
+		prefixes = append(prefixes,
+			filepath.Join(gopath, "pkg", "mod"),
+			filepath.Join(gopath, "src"))
+	}
+
+	return prefixes
+}
+
+// pathTrimmer removes absolute path prefixes from stack frame file names, as
+// they leak details of the build machine (eg. usernames).
+type pathTrimmer struct {
+	// Sorted longest first, so the most specific prefix wins.
+	prefixes []string
+}
+
+func newPathTrimmer(prefixes []string) *pathTrimmer {
+	var cleaned []string
+	for _, prefix := range prefixes {
+		if prefix == "" {
+			continue
+		}
+
+		cleaned = append(cleaned, strings.TrimSuffix(filepath.ToSlash(filepath.Clean(prefix)), "/"))
+	}
+
+	slices.SortFunc(cleaned, func(a, b string) int {
+		return cmp.Compare(len(b), len(a))
+	})
+
+	return &pathTrimmer{
+		prefixes: slices.Compact(cleaned),
+	}
+}
+
+// trim removes the first matching prefix from each frame's file name.
+func (t *pathTrimmer) trim(stackTrace []*v1alpha1.StackFrame) {
+	for _, frame := range stackTrace {
+		if frame == nil {
+			continue
+		}
+
+		for _, prefix := range t.prefixes {
+			if rest, ok := strings.CutPrefix(frame.File, prefix+"/"); ok {
+				frame.File = rest
+				break
+			}
+		}
+	}
+}
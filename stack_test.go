@@ -0,0 +1,134 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"go/build"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_TrimPathPrefix(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	_, thisFile, _, ok := runtime.Caller(0)
+	require.True(t, ok)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:        server.URL,
+		TrimPathPrefix: []string{"/home/ci/src/myapp/", filepath.Dir(thisFile)},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	goroot := filepath.ToSlash(build.Default.GOROOT)
+	gopath := filepath.ToSlash(filepath.SplitList(build.Default.GOPATH)[0])
+
+	require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Kind: v1alpha1.TelemetryEventKindError,
+		Name: "TestEvent",
+		StackTrace: []*v1alpha1.StackFrame{
+			{File: "/home/ci/src/myapp/internal/server.go", Function: "main.serve"},
+			{File: goroot + "/src/runtime/proc.go", Function: "runtime.main"},
+			{File: gopath + "/pkg/mod/github.com/foo/bar@v1.0.0/bar.go", Function: "github.com/foo/bar.Run"},
+			{File: "/home/ci/src/myapp-other/main.go", Function: "main.main"},
+		},
+	}))
+
+	event := receiveEvent(t, eventCh)
+	require.Len(t, event.StackTrace, 4)
+	assert.Equal(t, "internal/server.go", event.StackTrace[0].File)
+	assert.Equal(t, "runtime/proc.go", event.StackTrace[1].File)
+	assert.Equal(t, "github.com/foo/bar@v1.0.0/bar.go", event.StackTrace[2].File)
+	// Only whole path elements are trimmed.
+	assert.Equal(t, "/home/ci/src/myapp-other/main.go", event.StackTrace[3].File)
+
+	// Frames captured by ReportError should also be trimmed.
+	reporter.ReportError("TestError", errors.New("test"), nil)
+
+	event = receiveEvent(t, eventCh)
+	require.NotEmpty(t, event.StackTrace)
+	assert.Equal(t, "stack_test.go", event.StackTrace[0].File)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_MaxStackDepth(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		MaxStackDepth: 10,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	recurse(50, func() {
+		reporter.ReportError("TestError", errors.New("test"), nil)
+	})
+
+	// The top frames are kept, followed by a truncation marker.
+	event := receiveEvent(t, eventCh)
+	require.Len(t, event.StackTrace, 11)
+	assert.Contains(t, event.StackTrace[0].Function, "TestReporter_MaxStackDepth")
+	assert.Contains(t, event.StackTrace[1].Function, "recurse")
+	assert.Equal(t, "(truncated)", event.StackTrace[10].Function)
+
+	recurse(50, func() {
+		defer reporter.Recover()
+		panic("test")
+	})
+
+	event = receiveEvent(t, eventCh)
+	require.Len(t, event.StackTrace, 11)
+	assert.Contains(t, event.StackTrace[0].Function, "TestReporter_MaxStackDepth")
+	assert.Equal(t, "(truncated)", event.StackTrace[10].Function)
+
+	// Shallow stacks are not truncated.
+	reporter.ReportError("TestError", errors.New("test"), nil)
+
+	event = receiveEvent(t, eventCh)
+	require.NotEmpty(t, event.StackTrace)
+	assert.LessOrEqual(t, len(event.StackTrace), 10)
+	assert.NotEqual(t, "(truncated)", event.StackTrace[len(event.StackTrace)-1].Function)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+// recurse calls f from a stack n frames deep.
+//
+//go:noinline
+func recurse(n int, f func()) {
+	if n == 0 {
+		f()
+		return
+	}
+
+	recurse(n-1, f)
+}
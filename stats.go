@@ -0,0 +1,151 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// Stats are counters describing the activity of a reporter.
+type Stats struct {
+	// Reported is the number of events that were successfully sent.
+	Reported uint64
+	// Failed is the number of events that failed to send.
+	Failed uint64
+	// Failures is the number of events that failed to send, broken down by
+	// the reason they failed (eg. to tell a network outage from the server
+	// rejecting events).
+	Failures map[FailureReason]uint64
+	// Dropped is the number of events that were dropped rather than sent,
+	// broken down by the reason they were dropped.
+	Dropped map[DropReason]uint64
+	// QueueDepth is the number of reports (events, or batches of events)
+	// accepted but waiting to be sent.
+	QueueDepth int
+	// Latency is a histogram of how long each report (of an event, or batch of
+	// events) to the telemetry server took, whether or not it succeeded.
+	Latency LatencyStats
+}
+
+// TotalDropped returns the total number of dropped events.
+func (s Stats) TotalDropped() uint64 {
+	var total uint64
+	for _, n := range s.Dropped {
+		total += n
+	}
+	return total
+}
+
+type stats struct {
+	reported atomic.Uint64
+	failed   atomic.Uint64
+	// Populated on creation for every failure reason, so is safe to read
+	// concurrently.
+	failures map[FailureReason]*atomic.Uint64
+	// Populated on creation for every drop reason, so is safe to read concurrently.
+	dropped map[DropReason]*atomic.Uint64
+	latency *latencyHistogram
+	mu      sync.Mutex
+	// Closed (and replaced) whenever an event is reported.
+	reportedCh chan struct{}
+}
+
+func newStats() *stats {
+	s := &stats{
+		failures:   make(map[FailureReason]*atomic.Uint64, len(failureReasons)),
+		dropped:    make(map[DropReason]*atomic.Uint64, len(dropReasons)),
+		latency:    newLatencyHistogram(),
+		reportedCh: make(chan struct{}),
+	}
+
+	for _, reason := range failureReasons {
+		s.failures[reason] = &atomic.Uint64{}
+	}
+
+	for _, reason := range dropReasons {
+		s.dropped[reason] = &atomic.Uint64{}
+	}
+
+	return s
+}
+
+// addReported records that an event was successfully sent, waking any
+// waiters.
+func (s *stats) addReported() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reported.Add(1)
+
+	close(s.reportedCh)
+	s.reportedCh = make(chan struct{})
+}
+
+// addFailed records that an event failed to send with err.
+func (s *stats) addFailed(err error) {
+	s.failed.Add(1)
+	s.failures[classifyFailure(err)].Add(1)
+}
+
+// waitReported blocks until at least n events have been successfully sent.
+func (s *stats) waitReported(ctx context.Context, n uint64) error {
+	for {
+		s.mu.Lock()
+		reported := s.reported.Load()
+		reportedCh := s.reportedCh
+		s.mu.Unlock()
+
+		if reported >= n {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-reportedCh:
+		}
+	}
+}
+
+func (s *stats) snapshot() Stats {
+	snapshot := Stats{
+		Reported: s.reported.Load(),
+		Failed:   s.failed.Load(),
+		Failures: make(map[FailureReason]uint64, len(s.failures)),
+		Dropped:  make(map[DropReason]uint64, len(s.dropped)),
+		Latency:  s.latency.snapshot(),
+	}
+
+	for reason, n := range s.failures {
+		snapshot.Failures[reason] = n.Load()
+	}
+
+	for reason, n := range s.dropped {
+		snapshot.Dropped[reason] = n.Load()
+	}
+
+	return snapshot
+}
+
+// Stats returns a snapshot of the reporter's counters.
+func (r *Reporter) Stats() Stats {
+	stats := r.stats.snapshot()
+	stats.QueueDepth = r.queue.depth()
+
+	return stats
+}
+
+// WaitForDelivered blocks until at least n events have been successfully sent
+// (as counted by Stats().Reported), or ctx expires.
+func (r *Reporter) WaitForDelivered(ctx context.Context, n int) error {
+	return r.stats.waitReported(ctx, uint64(max(n, 0)))
+}
@@ -0,0 +1,367 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_StatsDropped(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		TagSampleRates: map[string]float64{
+			"debug": 0,
+		},
+		MaxPayloadBytes: 1024,
+		WarmupEvents:    1,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// Suppressed as part of the warmup.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "WarmupEvent",
+	})
+
+	// Sampled out.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "DebugEvent",
+		Tags: []string{"debug"},
+	})
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "DebugEvent",
+		Tags: []string{"debug"},
+	})
+
+	// Too large to send, even after truncation.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: strings.Repeat("x", 2048),
+	})
+
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	// Rejected as the reporter is shutting down.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	// Disabled (which takes precedence over shutting down).
+	reporter.Disable()
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no telemetry event, but got: %v", event)
+	default:
+	}
+
+	stats := reporter.Stats()
+	assert.Zero(t, stats.Reported)
+	assert.Zero(t, stats.Failed)
+	assert.Equal(t, map[telemetry.DropReason]uint64{
+		telemetry.DropReasonDisabled:     1,
+		telemetry.DropReasonShuttingDown: 1,
+		telemetry.DropReasonQueueFull:    0,
+		telemetry.DropReasonSampled:      2,
+		telemetry.DropReasonSuppressed:   1,
+		telemetry.DropReasonTooLarge:     1,
+		telemetry.DropReasonExpired:      0,
+		telemetry.DropReasonBlocked:      0,
+		telemetry.DropReasonInvalid:      0,
+		telemetry.DropReasonFiltered:     0,
+		telemetry.DropReasonRejected:     0,
+		telemetry.DropReasonPaused:       0,
+	}, stats.Dropped)
+	assert.Equal(t, uint64(6), stats.TotalDropped())
+}
+
+func TestReporter_StatsQueueFull(t *testing.T) {
+	// Start a mock telemetry server that holds requests until released.
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:                   server.URL,
+		ReservedHighPrioritySlots: -1,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// Fill every in-flight slot, and then some.
+	const overflow = 4
+	for i := 0; i < telemetry.MaxConcurrentReports+overflow; i++ {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+	}
+
+	close(release)
+
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	stats := reporter.Stats()
+	assert.Equal(t, uint64(telemetry.MaxConcurrentReports), stats.Reported)
+	assert.Zero(t, stats.Failed)
+	assert.Equal(t, uint64(overflow), stats.Dropped[telemetry.DropReasonQueueFull])
+	assert.Equal(t, uint64(overflow), stats.TotalDropped())
+}
+
+func TestReporter_StatsFailed(t *testing.T) {
+	// Start a mock telemetry server that always fails.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+	require.Error(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	}))
+
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	stats := reporter.Stats()
+	assert.Zero(t, stats.Reported)
+	assert.Equal(t, uint64(2), stats.Failed)
+	assert.Zero(t, stats.TotalDropped())
+}
+
+func TestReporter_StatsFailures(t *testing.T) {
+	// Start a mock telemetry server that responds with the requested status
+	// code, or doesn't respond until the test is complete.
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("status") {
+		case "hang":
+			<-release
+		case "400":
+			w.WriteHeader(http.StatusBadRequest)
+		case "500":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	t.Cleanup(server.Close)
+	t.Cleanup(func() { close(release) })
+
+	// A server that is no longer listening.
+	closedServer := httptest.NewServer(http.NotFoundHandler())
+	closedServer.Close()
+
+	// Route requests to the mock server based on the desired outcome.
+	withStatus := func(status string) func(*http.Request) error {
+		return func(req *http.Request) error {
+			req.URL.RawQuery = "status=" + status
+			return nil
+		}
+	}
+
+	tests := []struct {
+		name    string
+		conf    telemetry.Configuration
+		timeout time.Duration
+		want    telemetry.FailureReason
+	}{
+		{
+			name: "Network",
+			conf: telemetry.Configuration{BaseURL: closedServer.URL},
+			want: telemetry.FailureReasonNetwork,
+		},
+		{
+			name:    "Timeout",
+			conf:    telemetry.Configuration{BaseURL: server.URL, RequestHook: withStatus("hang")},
+			timeout: 50 * time.Millisecond,
+			want:    telemetry.FailureReasonTimeout,
+		},
+		{
+			name: "Client Error",
+			conf: telemetry.Configuration{BaseURL: server.URL, RequestHook: withStatus("400")},
+			want: telemetry.FailureReasonClientError,
+		},
+		{
+			name: "Server Error",
+			conf: telemetry.Configuration{BaseURL: server.URL, RequestHook: withStatus("500")},
+			want: telemetry.FailureReasonServerError,
+		},
+		{
+			name: "Marshal",
+			conf: telemetry.Configuration{
+				BaseURL: server.URL,
+				Marshaler: func(*v1alpha1.TelemetryEvent) ([]byte, error) {
+					return nil, errors.New("unsupported value")
+				},
+			},
+			want: telemetry.FailureReasonMarshal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Create a new telemetry reporter.
+			ctx := context.Background()
+			reporter := telemetry.NewReporter(ctx, slog.Default(), tt.conf)
+
+			reportCtx := ctx
+			if tt.timeout > 0 {
+				var cancel context.CancelFunc
+				reportCtx, cancel = context.WithTimeout(ctx, tt.timeout)
+				t.Cleanup(cancel)
+			}
+
+			require.Error(t, reporter.ReportEventSync(reportCtx, &v1alpha1.TelemetryEvent{
+				Name: "TestEvent",
+			}))
+
+			// Shutdown the reporter to ensure graceful exit.
+			require.NoError(t, reporter.Shutdown(ctx))
+
+			stats := reporter.Stats()
+			assert.Equal(t, uint64(1), stats.Failed)
+			assert.Zero(t, stats.Reported)
+
+			for reason, n := range stats.Failures {
+				if reason == tt.want {
+					assert.Equal(t, uint64(1), n, reason)
+				} else {
+					assert.Zero(t, n, reason)
+				}
+			}
+		})
+	}
+}
+
+func TestReporter_WaitForDelivered(t *testing.T) {
+	const events = 5
+
+	// Start a mock telemetry server.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for i := 0; i < events; i++ {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	t.Cleanup(cancel)
+
+	require.NoError(t, reporter.WaitForDelivered(waitCtx, events))
+	assert.Equal(t, uint64(events), reporter.Stats().Reported)
+
+	// Waiting for more events than will ever be delivered times out.
+	waitCtx, cancel = context.WithTimeout(ctx, 50*time.Millisecond)
+	t.Cleanup(cancel)
+
+	require.ErrorIs(t, reporter.WaitForDelivered(waitCtx, events+1), context.DeadlineExceeded)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_StatsLatency(t *testing.T) {
+	const (
+		events = 3
+		delay  = 30 * time.Millisecond
+	)
+
+	// Start a mock telemetry server that responds slowly.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(delay)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	assert.Zero(t, reporter.Stats().Latency.Quantile(0.5))
+
+	for i := 0; i < events; i++ {
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		}))
+	}
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	latency := reporter.Stats().Latency
+	assert.Equal(t, uint64(events), latency.Count)
+	assert.GreaterOrEqual(t, latency.Sum, events*delay)
+	assert.GreaterOrEqual(t, latency.Max, delay)
+	assert.GreaterOrEqual(t, latency.Mean(), delay)
+
+	// Every report took longer than the delay, so falls in a bucket above it.
+	assert.GreaterOrEqual(t, latency.Quantile(0.5), 25*time.Millisecond)
+	assert.LessOrEqual(t, latency.Quantile(0.99), latency.Max)
+
+	// The buckets are cumulative, with the last counting every report.
+	require.NotEmpty(t, latency.Buckets)
+	assert.Equal(t, uint64(events), latency.Buckets[len(latency.Buckets)-1].Count)
+	for _, bucket := range latency.Buckets {
+		if bucket.UpperBound < delay {
+			assert.Zero(t, bucket.Count)
+		}
+	}
+}
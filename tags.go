@@ -0,0 +1,52 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+// mergeTags returns a new slice containing the event tags followed by the
+// reporter tags, with any duplicates removed. Neither input slice is modified.
+func mergeTags(eventTags, reporterTags []string) []string {
+	if len(eventTags) == 0 && len(reporterTags) == 0 {
+		return nil
+	}
+
+	merged := make([]string, 0, len(eventTags)+len(reporterTags))
+	seen := make(map[string]struct{}, len(eventTags)+len(reporterTags))
+	for _, tags := range [][]string{eventTags, reporterTags} {
+		for _, tag := range tags {
+			if _, ok := seen[tag]; ok {
+				continue
+			}
+
+			seen[tag] = struct{}{}
+			merged = append(merged, tag)
+		}
+	}
+
+	return merged
+}
+
+// limitTags truncates tags to at most maxTags (if positive), keeping the
+// first. It reports whether any tags were removed.
+func limitTags(tags []string, maxTags int) ([]string, bool) {
+	if maxTags <= 0 || len(tags) <= maxTags {
+		return tags, false
+	}
+
+	return tags[:maxTags:maxTags], true
+}
+
+// WithTags returns a child reporter that adds the given tags to every event,
+// in addition to the tags of its parent. The child shares the parent's
+// connection, queue and lifecycle, shutting down either shuts down both.
+func (r *Reporter) WithTags(tags ...string) *Reporter {
+	child := *r
+	child.tags = mergeTags(tags, r.tags)
+	return &child
+}
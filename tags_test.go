@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_TagMerging(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Tags:    []string{"reporter-tag", "shared-tag"},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// Leave spare capacity so that appending would write into the caller's
+	// backing array.
+	tags := make([]string, 2, 8)
+	tags[0] = "event-tag"
+	tags[1] = "shared-tag"
+
+	event := &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+		Tags: tags,
+	}
+
+	// Report the same event twice.
+	for i := 0; i < 2; i++ {
+		reporter.ReportEvent(event)
+
+		received := receiveEvent(t, eventCh)
+		assert.Equal(t, []string{"event-tag", "shared-tag", "reporter-tag"}, received.Tags)
+	}
+
+	// The caller's event and backing array should be untouched.
+	assert.Equal(t, []string{"event-tag", "shared-tag"}, event.Tags)
+	assert.Equal(t, []string{"event-tag", "shared-tag", ""}, tags[:3])
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_WithTags(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Tags:    []string{"app", "shared-tag"},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	child := reporter.WithTags("subsystem:auth", "shared-tag")
+	grandchild := child.WithTags("component:tokens")
+
+	child.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+		Tags: []string{"event-tag"},
+	})
+
+	received := receiveEvent(t, eventCh)
+	assert.Equal(t, []string{"event-tag", "subsystem:auth", "shared-tag", "app"}, received.Tags)
+
+	grandchild.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	received = receiveEvent(t, eventCh)
+	assert.Equal(t, []string{"component:tokens", "subsystem:auth", "shared-tag", "app"}, received.Tags)
+
+	// The parent's tags should be unaffected.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	received = receiveEvent(t, eventCh)
+	assert.Equal(t, []string{"app", "shared-tag"}, received.Tags)
+
+	// Shutting down the parent also shuts down the child.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	err := child.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+	require.ErrorIs(t, err, telemetry.ErrShuttingDown)
+}
+
+func TestReporter_WithoutReporterTags(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Tags:    []string{"reporter-tag"},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEventCtx(telemetry.WithoutReporterTags(ctx), &v1alpha1.TelemetryEvent{
+		Name: "DiagnosticEvent",
+		Tags: []string{"diagnostic"},
+	})
+
+	received := receiveEvent(t, eventCh)
+	assert.Equal(t, []string{"diagnostic"}, received.Tags)
+
+	// Other events still carry the reporter's tags.
+	reporter.ReportEventCtx(ctx, &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+		Tags: []string{"event-tag"},
+	})
+
+	received = receiveEvent(t, eventCh)
+	assert.Equal(t, []string{"event-tag", "reporter-tag"}, received.Tags)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_MaxTags(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		Tags:    []string{"reporter-tag", "shared-tag"},
+		MaxTags: 3,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// Duplicates don't count towards the limit.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+		Tags: []string{"event-tag", "shared-tag", "event-tag"},
+	})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, []string{"event-tag", "shared-tag", "reporter-tag"}, event.Tags)
+	assert.NotContains(t, event.Values, "telemetry_truncated")
+
+	// The event's own tags are kept in preference to the reporter's.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+		Tags: []string{"a", "b", "c", "d"},
+	})
+
+	event = receiveEvent(t, eventCh)
+	assert.Equal(t, []string{"a", "b", "c"}, event.Tags)
+	assert.Equal(t, "true", event.Values["telemetry_truncated"])
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"log/slog"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// teeCopy returns a copy of the event for the configured channel, or nil if
+// there is none. The copy is taken before the event is queued, as it may be
+// modified (eg. by middleware) once it is being sent.
+func (r *Reporter) teeCopy(event *v1alpha1.TelemetryEvent) *v1alpha1.TelemetryEvent {
+	if r.teeCh == nil {
+		return nil
+	}
+
+	return event.DeepCopy()
+}
+
+// tee sends the copy of an event accepted for sending (see teeCopy) to the
+// configured channel, for in-process consumers. It never blocks, if the
+// channel is full the copy is discarded (the event itself is still sent).
+func (r *Reporter) tee(copied *v1alpha1.TelemetryEvent) {
+	if copied == nil {
+		return
+	}
+
+	select {
+	case r.teeCh <- copied:
+	default:
+		r.logger.Debug("Tee channel is full, discarding copy of event", slog.String("name", copied.Name))
+	}
+}
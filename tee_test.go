@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_Tee(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	teeCh := make(chan *v1alpha1.TelemetryEvent, 2)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:       server.URL,
+		Tags:          []string{"reporter-tag"},
+		BlockedEvents: []string{"Blocked"},
+		Tee:           teeCh,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	// More events than the tee channel can hold.
+	for i := 0; i < 3; i++ {
+		require.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: fmt.Sprintf("Event%d", i),
+		}))
+
+		// Every event still reaches the server.
+		event := receiveEvent(t, eventCh)
+		assert.Equal(t, fmt.Sprintf("Event%d", i), event.Name)
+	}
+
+	// Dropped events are not teed.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "Blocked",
+	})
+
+	// The tee receives enriched events, discarding any that didn't fit.
+	require.Len(t, teeCh, 2)
+	for i := 0; i < 2; i++ {
+		event := <-teeCh
+		assert.Equal(t, fmt.Sprintf("Event%d", i), event.Name)
+		assert.Equal(t, []string{"reporter-tag"}, event.Tags)
+		assert.NotEmpty(t, event.SessionID)
+		assert.NotNil(t, event.Timestamp)
+	}
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "Event3",
+	})
+
+	event := <-teeCh
+	assert.Equal(t, "Event3", event.Name)
+	assert.Equal(t, "Event3", receiveEvent(t, eventCh).Name)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_TeeQueueFull(t *testing.T) {
+	const (
+		queueSize = 4
+		overflow  = 3
+	)
+
+	// Start a mock telemetry server that holds requests until released.
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-release
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	total := telemetry.MaxConcurrentReports + queueSize + overflow
+	teeCh := make(chan *v1alpha1.TelemetryEvent, total)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL:                   server.URL,
+		QueueSize:                 queueSize,
+		ReservedHighPrioritySlots: -1,
+		Tee:                       teeCh,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for i := 0; i < total; i++ {
+		reporter.ReportInfo("TestEvent", nil)
+	}
+
+	// Events dropped because the queue is full are not teed.
+	assert.Equal(t, uint64(overflow), reporter.Stats().Dropped[telemetry.DropReasonQueueFull])
+	assert.Len(t, teeCh, total-overflow)
+
+	close(release)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
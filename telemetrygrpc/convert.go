@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetrygrpc
+
+import (
+	"github.com/dpeckett/telemetry/telemetrygrpc/telemetrypb"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// EventToProto converts an event into its protobuf representation.
+func EventToProto(event *v1alpha1.TelemetryEvent) *telemetrypb.TelemetryEvent {
+	if event == nil {
+		return nil
+	}
+
+	out := &telemetrypb.TelemetryEvent{
+		SchemaVersion: event.SchemaVersion,
+		SessionId:     event.SessionID,
+		ParentId:      event.ParentID,
+		CorrelationId: event.CorrelationID,
+		Kind:          string(event.Kind),
+		Priority:      string(event.Priority),
+		Name:          event.Name,
+		Message:       event.Message,
+		Values:        event.Values,
+		Tags:          event.Tags,
+	}
+
+	if event.Timestamp != nil {
+		out.Timestamp = timestamppb.New(*event.Timestamp)
+	}
+
+	for _, frame := range event.StackTrace {
+		if frame == nil {
+			continue
+		}
+
+		out.StackTrace = append(out.StackTrace, &telemetrypb.StackFrame{
+			File:     frame.File,
+			Function: frame.Function,
+			Line:     frame.Line,
+			Column:   frame.Column,
+		})
+	}
+
+	return out
+}
+
+// EventFromProto converts the protobuf representation of an event back into
+// an event, eg. for use by a server implementing the telemetry service.
+func EventFromProto(event *telemetrypb.TelemetryEvent) *v1alpha1.TelemetryEvent {
+	if event == nil {
+		return nil
+	}
+
+	out := &v1alpha1.TelemetryEvent{
+		SchemaVersion: event.GetSchemaVersion(),
+		SessionID:     event.GetSessionId(),
+		ParentID:      event.GetParentId(),
+		CorrelationID: event.GetCorrelationId(),
+		Kind:          v1alpha1.TelemetryEventKind(event.GetKind()),
+		Priority:      v1alpha1.TelemetryEventPriority(event.GetPriority()),
+		Name:          event.GetName(),
+		Message:       event.GetMessage(),
+		Values:        event.GetValues(),
+		Tags:          event.GetTags(),
+	}
+
+	if event.Timestamp != nil {
+		timestamp := event.Timestamp.AsTime()
+		out.Timestamp = &timestamp
+	}
+
+	for _, frame := range event.GetStackTrace() {
+		out.StackTrace = append(out.StackTrace, &v1alpha1.StackFrame{
+			File:     frame.GetFile(),
+			Function: frame.GetFunction(),
+			Line:     frame.GetLine(),
+			Column:   frame.GetColumn(),
+		})
+	}
+
+	return out
+}
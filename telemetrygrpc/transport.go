@@ -0,0 +1,89 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+// Package telemetrygrpc provides a gRPC transport for telemetry events, as an
+// alternative to the default HTTP/JSON transport. It is kept separate from the
+// telemetry package so that the gRPC dependency is only pulled in when it is
+// actually used.
+//
+// The service is generated from telemetrypb/telemetry.proto, so servers use
+// the standard protobuf codec and implement
+// telemetrypb.TelemetryServiceServer (converting received events with
+// EventFromProto).
+package telemetrygrpc
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/telemetrygrpc/telemetrypb"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"google.golang.org/grpc"
+)
+
+var _ telemetry.Transport = (*Transport)(nil)
+
+// Transport sends telemetry events to a telemetry gRPC service (as defined by
+// telemetrypb/telemetry.proto).
+//
+// To use it, supply it as the transport of the telemetry reporter:
+//
+//	conn, err := grpc.NewClient("telemetry.example.com:443", ...)
+//	...
+//	conf := telemetry.Configuration{
+//		Transport: telemetrygrpc.NewTransport(conn),
+//	}
+type Transport struct {
+	client telemetrypb.TelemetryServiceClient
+}
+
+// NewTransport creates a transport that sends events over the given gRPC
+// connection. The connection is owned by the caller, and must be closed once
+// the reporter has shut down.
+func NewTransport(conn grpc.ClientConnInterface) *Transport {
+	return &Transport{client: telemetrypb.NewTelemetryServiceClient(conn)}
+}
+
+// ReportEvent implements telemetry.Transport.
+func (t *Transport) ReportEvent(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+	if _, err := t.client.ReportEvent(ctx, &telemetrypb.ReportEventRequest{Event: EventToProto(event)}); err != nil {
+		return fmt.Errorf("failed to report event: %w", err)
+	}
+
+	return nil
+}
+
+// ReportEvents implements telemetry.Transport. If the server rejects some of
+// the events, a *v1alpha1.RejectedEventsError is returned.
+func (t *Transport) ReportEvents(ctx context.Context, events []*v1alpha1.TelemetryEvent) error {
+	req := &telemetrypb.ReportEventsRequest{
+		Events: make([]*telemetrypb.TelemetryEvent, len(events)),
+	}
+	for i, event := range events {
+		req.Events[i] = EventToProto(event)
+	}
+
+	resp, err := t.client.ReportEvents(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to report events: %w", err)
+	}
+
+	if len(resp.GetRejected()) == 0 {
+		return nil
+	}
+
+	rejected := make(map[int]string, len(resp.GetRejected()))
+	for _, e := range resp.GetRejected() {
+		rejected[int(e.GetIndex())] = e.GetReason()
+	}
+
+	return &v1alpha1.RejectedEventsError{Rejected: rejected}
+}
@@ -0,0 +1,136 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetrygrpc_test
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/telemetrygrpc"
+	"github.com/dpeckett/telemetry/telemetrygrpc/telemetrypb"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestTransport(t *testing.T) {
+	srv := &mockServer{
+		eventCh: make(chan *v1alpha1.TelemetryEvent, 8),
+		batchCh: make(chan []*v1alpha1.TelemetryEvent, 8),
+	}
+	conn := startServer(t, srv)
+
+	ctx := context.Background()
+
+	t.Run("Reporter", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), telemetry.Configuration{
+			Transport: telemetrygrpc.NewTransport(conn),
+			Tags:      []string{"grpc"},
+			AllowInCI: true,
+		})
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name:   "TestEvent",
+			Values: map[string]string{"key": "value"},
+		})
+
+		select {
+		case event := <-srv.eventCh:
+			assert.Equal(t, "TestEvent", event.Name)
+			assert.Equal(t, map[string]string{"key": "value"}, event.Values)
+			assert.Equal(t, []string{"grpc"}, event.Tags)
+			assert.NotEmpty(t, event.SessionID)
+			assert.NotNil(t, event.Timestamp)
+		case <-time.After(time.Second):
+			t.Fatal("Timeout waiting for telemetry event")
+		}
+
+		require.NoError(t, reporter.Shutdown(ctx))
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		transport := telemetrygrpc.NewTransport(conn)
+
+		err := transport.ReportEvents(ctx, []*v1alpha1.TelemetryEvent{
+			{Name: "Event1"},
+			{Name: "Rejected"},
+		})
+
+		var rejectedErr *v1alpha1.RejectedEventsError
+		require.ErrorAs(t, err, &rejectedErr)
+		assert.Equal(t, map[int]string{1: "invalid event"}, rejectedErr.Rejected)
+
+		batch := <-srv.batchCh
+		require.Len(t, batch, 2)
+		assert.Equal(t, "Event1", batch[0].Name)
+	})
+}
+
+type mockServer struct {
+	telemetrypb.UnimplementedTelemetryServiceServer
+	eventCh chan *v1alpha1.TelemetryEvent
+	batchCh chan []*v1alpha1.TelemetryEvent
+}
+
+func (s *mockServer) ReportEvent(_ context.Context, req *telemetrypb.ReportEventRequest) (*telemetrypb.ReportResponse, error) {
+	s.eventCh <- telemetrygrpc.EventFromProto(req.GetEvent())
+	return &telemetrypb.ReportResponse{}, nil
+}
+
+func (s *mockServer) ReportEvents(_ context.Context, req *telemetrypb.ReportEventsRequest) (*telemetrypb.ReportResponse, error) {
+	var (
+		events []*v1alpha1.TelemetryEvent
+		resp   telemetrypb.ReportResponse
+	)
+	for i, event := range req.GetEvents() {
+		events = append(events, telemetrygrpc.EventFromProto(event))
+
+		if event.GetName() == "Rejected" {
+			resp.Rejected = append(resp.Rejected, &telemetrypb.RejectedEvent{Index: int32(i), Reason: "invalid event"})
+		}
+	}
+
+	s.batchCh <- events
+
+	return &resp, nil
+}
+
+// startServer starts an in-process gRPC telemetry server, returning a client
+// connection to it.
+func startServer(t *testing.T, srv telemetrypb.TelemetryServiceServer) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+
+	s := grpc.NewServer()
+	telemetrypb.RegisterTelemetryServiceServer(s, srv)
+
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = conn.Close()
+	})
+
+	return conn
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+// Package telemetryotel provides OpenTelemetry tracing for telemetry uploads.
+// It is kept separate from the telemetry package so that the OpenTelemetry
+// dependency is only pulled in when it is actually used.
+package telemetryotel
+
+import (
+	"fmt"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Transport is an http.RoundTripper that injects the W3C trace context
+// (traceparent/tracestate) from the request context into outgoing telemetry
+// uploads, and optionally records a span around each upload. The trace context
+// is taken from the context passed to ReportEventCtx or ReportEventSync.
+// Asynchronous events reported without one (eg. with ReportEvent), and
+// batched events, use the context the reporter was created with.
+//
+// To use it, supply it as the transport of the telemetry reporter's HTTP client:
+//
+//	conf := telemetry.Configuration{
+//		HTTPClient: &http.Client{
+//			Transport: &telemetryotel.Transport{Tracer: tracer},
+//		},
+//	}
+type Transport struct {
+	// Base is the optional underlying round tripper, defaults to
+	// http.DefaultTransport.
+	Base http.RoundTripper
+	// Tracer is the optional tracer used to start a span around each upload.
+	// If nil, the trace context is propagated but no span is started.
+	Tracer trace.Tracer
+	// Propagator is the optional propagator used to inject the trace context,
+	// defaults to the W3C trace context propagator.
+	Propagator propagation.TextMapPropagator
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	propagator := t.Propagator
+	if propagator == nil {
+		propagator = propagation.TraceContext{}
+	}
+
+	ctx := req.Context()
+
+	var span trace.Span
+	if t.Tracer != nil {
+		ctx, span = t.Tracer.Start(ctx, "telemetry.upload",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attribute.String("http.request.method", req.Method),
+				attribute.String("url.full", req.URL.String()),
+			))
+		defer span.End()
+	}
+
+	// RoundTrippers must not modify the original request.
+	req = req.Clone(ctx)
+	propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	resp, err := base.RoundTrip(req)
+	if span != nil {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		} else {
+			span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+			if resp.StatusCode >= http.StatusBadRequest {
+				span.SetStatus(codes.Error, fmt.Sprintf("unexpected status code: %d", resp.StatusCode))
+			}
+		}
+	}
+
+	return resp, err
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetryotel_test
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/telemetryotel"
+	"github.com/dpeckett/telemetry/v1alpha1"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransport(t *testing.T) {
+	headerCh := make(chan http.Header, 2)
+
+	// Start a mock telemetry server that captures the request headers.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerCh <- r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	})
+
+	tracer := tracerProvider.Tracer("telemetry-test")
+
+	// Uploads should be children of the span in the reporter's context.
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	t.Cleanup(func() { parent.End() })
+
+	conf := telemetry.Configuration{
+		BaseURL:   server.URL,
+		AllowInCI: true,
+		HTTPClient: &http.Client{
+			Transport: &telemetryotel.Transport{Tracer: tracer},
+		},
+	}
+
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for i := 0; i < 2; i++ {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+	}
+
+	for i := 0; i < 2; i++ {
+		select {
+		case header := <-headerCh:
+			assert.NotEmpty(t, header.Get("traceparent"))
+		case <-time.After(1 * time.Second):
+			t.Fatal("Timeout waiting for telemetry event")
+		}
+	}
+
+	require.NoError(t, reporter.Shutdown(context.Background()))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	for _, span := range spans {
+		assert.Equal(t, "telemetry.upload", span.Name())
+		assert.Equal(t, parent.SpanContext().TraceID(), span.SpanContext().TraceID())
+		assert.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+	}
+}
+
+func TestTransport_EventContext(t *testing.T) {
+	headerCh := make(chan http.Header, 1)
+
+	// Start a mock telemetry server that captures the request headers.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerCh <- r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	})
+
+	tracer := tracerProvider.Tracer("telemetry-test")
+
+	conf := telemetry.Configuration{
+		BaseURL:   server.URL,
+		AllowInCI: true,
+		HTTPClient: &http.Client{
+			Transport: &telemetryotel.Transport{Tracer: tracer},
+		},
+	}
+
+	// The reporter's context carries no trace context.
+	reporter := telemetry.NewReporter(context.Background(), slog.Default(), conf)
+
+	// Uploads should be children of the span in the caller's context.
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	t.Cleanup(func() { parent.End() })
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	reporter.ReportEventCtx(ctx, &v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	// The event is sent asynchronously, so cancelling the caller's context
+	// should have no effect.
+	cancel()
+
+	select {
+	case header := <-headerCh:
+		assert.Contains(t, header.Get("traceparent"), parent.SpanContext().TraceID().String())
+	case <-time.After(1 * time.Second):
+		t.Fatal("Timeout waiting for telemetry event")
+	}
+
+	require.NoError(t, reporter.Shutdown(context.Background()))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext().TraceID())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func TestTransport_NoTracer(t *testing.T) {
+	headerCh := make(chan http.Header, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerCh <- r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	tracerProvider := sdktrace.NewTracerProvider()
+	t.Cleanup(func() {
+		_ = tracerProvider.Shutdown(context.Background())
+	})
+
+	ctx, parent := tracerProvider.Tracer("telemetry-test").Start(context.Background(), "parent")
+	t.Cleanup(func() { parent.End() })
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+	require.NoError(t, err)
+
+	resp, err := (&http.Client{Transport: &telemetryotel.Transport{}}).Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	// Without a tracer, the parent trace context is propagated as-is.
+	header := <-headerCh
+	assert.Contains(t, header.Get("traceparent"), parent.SpanContext().SpanID().String())
+
+	// The original request should not have been modified.
+	assert.Empty(t, req.Header.Get("traceparent"))
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+// Package telemetrytest provides utilities for testing code that reports
+// telemetry events.
+package telemetrytest
+
+import (
+	"context"
+	"sync"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+var _ telemetry.EventReporter = (*RecordingReporter)(nil)
+
+// RecordingReporter is a telemetry.EventReporter that records every reported
+// event, rather than sending it. It is safe for concurrent use.
+type RecordingReporter struct {
+	mu     sync.Mutex
+	events []*v1alpha1.TelemetryEvent
+}
+
+// NewRecordingReporter creates a new recording reporter.
+func NewRecordingReporter() *RecordingReporter {
+	return &RecordingReporter{}
+}
+
+// ReportEvent records a telemetry event.
+func (r *RecordingReporter) ReportEvent(event *v1alpha1.TelemetryEvent) {
+	r.ReportEventCtx(context.Background(), event)
+}
+
+// ReportEventCtx records a telemetry event, merging in any values attached
+// to the context with telemetry.WithValues.
+func (r *RecordingReporter) ReportEventCtx(ctx context.Context, event *v1alpha1.TelemetryEvent) {
+	if event == nil {
+		return
+	}
+
+	event = event.DeepCopy()
+
+	for k, v := range telemetry.ValuesFromContext(ctx) {
+		if _, ok := event.Values[k]; ok {
+			continue
+		}
+
+		if event.Values == nil {
+			event.Values = make(map[string]string)
+		}
+		event.Values[k] = v
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+// ReportEventSync records a telemetry event, it never returns an error.
+func (r *RecordingReporter) ReportEventSync(ctx context.Context, event *v1alpha1.TelemetryEvent) error {
+	r.ReportEventCtx(ctx, event)
+	return nil
+}
+
+// Events returns a copy of every recorded event, in the order they were
+// reported.
+func (r *RecordingReporter) Events() []*v1alpha1.TelemetryEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	events := make([]*v1alpha1.TelemetryEvent, len(r.events))
+	for i, event := range r.events {
+		events[i] = event.DeepCopy()
+	}
+
+	return events
+}
+
+// LastEvent returns a copy of the most recently recorded event, or nil if no
+// events have been recorded.
+func (r *RecordingReporter) LastEvent() *v1alpha1.TelemetryEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.events) == 0 {
+		return nil
+	}
+
+	return r.events[len(r.events)-1].DeepCopy()
+}
+
+// Contains returns whether an event with the given name has been recorded.
+func (r *RecordingReporter) Contains(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range r.events {
+		if event.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Reset discards every recorded event.
+func (r *RecordingReporter) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = nil
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetrytest_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/telemetrytest"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// login is an example call site under test.
+func login(ctx context.Context, reporter telemetry.EventReporter) {
+	reporter.ReportEventCtx(ctx, v1alpha1.NewEvent("Login").
+		Value("user_tier", "pro").
+		Build())
+}
+
+func TestRecordingReporter(t *testing.T) {
+	reporter := telemetrytest.NewRecordingReporter()
+
+	assert.Nil(t, reporter.LastEvent())
+	assert.False(t, reporter.Contains("Login"))
+
+	ctx := telemetry.WithValues(context.Background(), map[string]string{
+		"request_id": "abc123",
+	})
+
+	login(ctx, reporter)
+
+	require.True(t, reporter.Contains("Login"))
+
+	event := reporter.LastEvent()
+	require.NotNil(t, event)
+	assert.Equal(t, map[string]string{
+		"request_id": "abc123",
+		"user_tier":  "pro",
+	}, event.Values)
+
+	// Modifying the returned events shouldn't affect the recorded events.
+	event.Name = "Modified"
+	assert.Equal(t, "Login", reporter.LastEvent().Name)
+
+	// Record events concurrently.
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			require.NoError(t, reporter.ReportEventSync(context.Background(), &v1alpha1.TelemetryEvent{
+				Name: "Concurrent",
+			}))
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, reporter.Events(), 11)
+
+	reporter.Reset()
+	assert.Empty(t, reporter.Events())
+}
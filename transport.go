@@ -0,0 +1,246 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// TLSConfiguration is the TLS configuration used when connecting to the
+// telemetry server.
+type TLSConfiguration struct {
+	// CAFile is the optional path to a PEM encoded CA bundle used to verify
+	// the telemetry server. If a CA bundle is supplied, the system roots are
+	// not trusted.
+	CAFile string
+	// CA is an optional PEM encoded CA bundle, it is used in addition to CAFile.
+	CA []byte
+	// CertFile is the optional path to a PEM encoded client certificate.
+	CertFile string
+	// KeyFile is the optional path to the PEM encoded client certificate key.
+	KeyFile string
+	// Cert is an optional PEM encoded client certificate, used instead of CertFile.
+	Cert []byte
+	// Key is an optional PEM encoded client certificate key, used instead of KeyFile.
+	Key []byte
+	// InsecureSkipVerify disables verification of the telemetry server's
+	// certificate. This should only be used for testing.
+	InsecureSkipVerify bool
+}
+
+const (
+	// The default maximum number of idle connections kept open.
+	defaultMaxIdleConns = 100
+	// The default time an idle connection is kept open for.
+	defaultIdleConnTimeout = 90 * time.Second
+	// The default maximum time spent establishing a connection.
+	defaultDialTimeout = 30 * time.Second
+	// The default maximum time spent on a TLS handshake.
+	defaultTLSHandshakeTimeout = 10 * time.Second
+	// The interval between keep-alive probes of open connections.
+	keepAliveInterval = 30 * time.Second
+)
+
+// Creates the default HTTP client, replaceable for testing.
+var createHTTPClient = newHTTPClient
+
+// Dials connections to the telemetry server, replaceable for testing.
+var dialContext = (*net.Dialer).DialContext
+
+// newHTTPClient creates the HTTP client used for reporting when no explicit
+// HTTP client has been configured. Connections are kept alive between
+// reports, and HTTP/2 is used where the server supports it.
+func newHTTPClient(logger *slog.Logger, conf Configuration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	transport.MaxIdleConns = defaultMaxIdleConns
+	if conf.MaxIdleConns > 0 {
+		transport.MaxIdleConns = conf.MaxIdleConns
+	}
+	// Every event is sent to the same host, so allow enough idle connections
+	// to serve every in-flight report.
+	transport.MaxIdleConnsPerHost = min(maxConcurrentReports, transport.MaxIdleConns)
+
+	transport.IdleConnTimeout = defaultIdleConnTimeout
+	if conf.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = conf.IdleConnTimeout
+	}
+
+	transport.ForceAttemptHTTP2 = conf.ForceAttemptHTTP2 == nil || *conf.ForceAttemptHTTP2
+
+	// Bound the time spent connecting separately from the request timeout, as
+	// an unreachable server can otherwise hold up a report for much longer.
+	dialer := &net.Dialer{
+		Timeout:   defaultDialTimeout,
+		KeepAlive: keepAliveInterval,
+	}
+	if conf.DialTimeout > 0 {
+		dialer.Timeout = conf.DialTimeout
+	}
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return dialContext(dialer, ctx, network, address)
+	}
+
+	transport.TLSHandshakeTimeout = defaultTLSHandshakeTimeout
+	if conf.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = conf.TLSHandshakeTimeout
+	}
+
+	if conf.TLS != nil {
+		tlsConfig, err := newTLSConfig(conf.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create TLS config: %w", err)
+		}
+
+		transport.TLSClientConfig = tlsConfig
+	}
+
+	if conf.ProxyURL != "" {
+		proxyURL, err := parseProxyURL(conf.ProxyURL)
+		if err != nil {
+			logger.Warn("Invalid proxy URL, not using a proxy", slog.Any("error", err))
+
+			transport.Proxy = nil
+		} else {
+			transport.Proxy = http.ProxyURL(proxyURL)
+		}
+	}
+
+	if socketPath, ok := unixSocketPath(conf.BaseURL); ok {
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialContext(dialer, ctx, "unix", socketPath)
+		}
+		// There's no use for a proxy when connecting to a local socket.
+		transport.Proxy = nil
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+// validateBaseURL checks that the telemetry server base URL is usable.
+func validateBaseURL(baseURL string) error {
+	if _, ok := unixSocketPath(baseURL); ok {
+		return nil
+	}
+
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return fmt.Errorf("invalid base URL: %w", err)
+	}
+
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("invalid base URL %q: scheme must be http, https, or unix", baseURL)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("invalid base URL %q: missing host", baseURL)
+	}
+
+	return nil
+}
+
+// validateTLS checks that the TLS material used to connect to the telemetry
+// server can be loaded, so that a bad CA bundle or client certificate is
+// caught up front rather than when the first event is sent.
+func validateTLS(conf Configuration) error {
+	if conf.TLS == nil || conf.HTTPClient != nil {
+		return nil
+	}
+
+	if _, err := newTLSConfig(conf.TLS); err != nil {
+		return fmt.Errorf("failed to create TLS config: %w", err)
+	}
+
+	return nil
+}
+
+// unixSocketPath returns the path of the Unix domain socket, if the base URL
+// is of the form unix:///path/to/socket.
+func unixSocketPath(baseURL string) (string, bool) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme != "unix" || u.Path == "" {
+		return "", false
+	}
+
+	return u.Path, true
+}
+
+func parseProxyURL(rawURL string) (*url.URL, error) {
+	proxyURL, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	if proxyURL.Scheme == "" || proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy URL must include a scheme and host: %q", rawURL)
+	}
+
+	return proxyURL, nil
+}
+
+func newTLSConfig(conf *TLSConfiguration) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: conf.InsecureSkipVerify,
+	}
+
+	caPEM := conf.CA
+	if conf.CAFile != "" {
+		caFilePEM, err := os.ReadFile(conf.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+
+		caPEM = append(caFilePEM, caPEM...)
+	}
+
+	if len(caPEM) > 0 {
+		rootCAs := x509.NewCertPool()
+		if !rootCAs.AppendCertsFromPEM(caPEM) {
+			return nil, errors.New("no certificates found in CA bundle")
+		}
+
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	certPEM, keyPEM := conf.Cert, conf.Key
+	if conf.CertFile != "" || conf.KeyFile != "" {
+		var err error
+		certPEM, err = os.ReadFile(conf.CertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client certificate: %w", err)
+		}
+
+		keyPEM, err = os.ReadFile(conf.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read client certificate key: %w", err)
+		}
+	}
+
+	if len(certPEM) > 0 || len(keyPEM) > 0 {
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
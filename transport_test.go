@@ -0,0 +1,418 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_TLS(t *testing.T) {
+	handler, eventCh := mockTelemetryHandler(t)
+
+	// Start a mock telemetry server using TLS.
+	server := httptest.NewTLSServer(handler)
+	t.Cleanup(server.Close)
+
+	// Write out the server's self-signed certificate as the CA bundle.
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{
+		Type:  "CERTIFICATE",
+		Bytes: server.Certificate().Raw,
+	}), 0o644))
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		TLS: &telemetry.TLSConfiguration{
+			CAFile: caFile,
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "TestEvent", event.Name)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_MutualTLS(t *testing.T) {
+	handler, eventCh := mockTelemetryHandler(t)
+
+	clientCertPEM, clientKeyPEM := generateClientCertificate(t)
+
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM(clientCertPEM))
+
+	// Start a mock telemetry server that requires a client certificate.
+	server := httptest.NewUnstartedServer(handler)
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		TLS: &telemetry.TLSConfiguration{
+			CA: pem.EncodeToMemory(&pem.Block{
+				Type:  "CERTIFICATE",
+				Bytes: server.Certificate().Raw,
+			}),
+			Cert: clientCertPEM,
+			Key:  clientKeyPEM,
+		},
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "TestEvent", event.Name)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_InvalidTLS(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+		TLS: &telemetry.TLSConfiguration{
+			CAFile: filepath.Join(t.TempDir(), "nonexistent.pem"),
+		},
+	}
+
+	ctx := context.Background()
+
+	t.Run("With Error", func(t *testing.T) {
+		_, err := telemetry.NewReporterWithError(ctx, slog.Default(), conf)
+		require.ErrorContains(t, err, "failed to read CA bundle")
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+
+		// Shutdown the reporter to ensure graceful exit.
+		require.NoError(t, reporter.Shutdown(ctx))
+
+		// Nothing is sent over a client without the configured CA bundle.
+		assert.Empty(t, eventCh)
+	})
+}
+
+func generateClientCertificate(t *testing.T) (certPEM, keyPEM []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "telemetry-client"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM
+}
+
+func TestReporter_ProxyURL(t *testing.T) {
+	handler, eventCh := mockTelemetryHandler(t)
+
+	proxiedHostCh := make(chan string, 1)
+
+	// Start a stub proxy that serves the telemetry API itself.
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHostCh <- r.URL.Host
+
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(proxy.Close)
+
+	// Create a new telemetry reporter, the base URL is only reachable via the proxy.
+	conf := telemetry.Configuration{
+		BaseURL:  "http://telemetry.invalid",
+		ProxyURL: proxy.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "TestEvent", event.Name)
+	assert.Equal(t, "telemetry.invalid", <-proxiedHostCh)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_InvalidProxyURL(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter with a malformed proxy URL.
+	conf := telemetry.Configuration{
+		BaseURL:  server.URL,
+		ProxyURL: "not a url",
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	// Falls back to connecting directly.
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "TestEvent", event.Name)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_ConnectionReuse(t *testing.T) {
+	handler, eventCh := mockTelemetryHandler(t)
+
+	// Start a mock telemetry server that counts new connections.
+	var newConns atomic.Int64
+	server := httptest.NewUnstartedServer(handler)
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			newConns.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	conf := telemetry.Configuration{
+		BaseURL: server.URL,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	const (
+		senders         = 8
+		eventsPerSender = 20
+	)
+
+	go func() {
+		for range eventCh {
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := 0; i < senders; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < eventsPerSender; j++ {
+				assert.NoError(t, reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+					Name: "TestEvent",
+				}))
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Connections are kept alive and reused, rather than one per event.
+	assert.LessOrEqual(t, newConns.Load(), int64(senders))
+
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_UnixSocket(t *testing.T) {
+	handler, eventCh := mockTelemetryHandler(t)
+
+	// Start a mock telemetry server listening on a Unix domain socket.
+	socketPath := filepath.Join(t.TempDir(), "telemetry.sock")
+	lis, err := net.Listen("unix", socketPath)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(handler)
+	server.Listener = lis
+	server.Start()
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter.
+	conf := telemetry.Configuration{
+		BaseURL: "unix://" + socketPath,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "TestEvent",
+	})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "TestEvent", event.Name)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}
+
+func TestReporter_ConnectTimeouts(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Dial", func(t *testing.T) {
+		// Connection attempts hang until the dialer times out.
+		dialTimeoutCh := make(chan time.Duration, 1)
+		t.Cleanup(telemetry.SetDialContext(func(d *net.Dialer, ctx context.Context, _, _ string) (net.Conn, error) {
+			select {
+			case dialTimeoutCh <- d.Timeout:
+			default:
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, d.Timeout)
+			defer cancel()
+
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}))
+
+		conf := telemetry.Configuration{
+			BaseURL:     "http://telemetry.invalid",
+			DialTimeout: 100 * time.Millisecond,
+			MaxRetries:  1,
+		}
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Close())
+		})
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		start := time.Now()
+		err := reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, 100*time.Millisecond, <-dialTimeoutCh)
+
+		// Fails within the dial timeout, rather than the request timeout.
+		assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.NoError(t, ctx.Err())
+	})
+
+	t.Run("TLS Handshake", func(t *testing.T) {
+		// A server that accepts connections, but never responds.
+		lis, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			_ = lis.Close()
+		})
+
+		go func() {
+			var conns []net.Conn
+			defer func() {
+				for _, conn := range conns {
+					_ = conn.Close()
+				}
+			}()
+
+			for {
+				conn, err := lis.Accept()
+				if err != nil {
+					return
+				}
+				conns = append(conns, conn)
+			}
+		}()
+
+		conf := telemetry.Configuration{
+			BaseURL:             "https://" + lis.Addr().String(),
+			TLSHandshakeTimeout: 100 * time.Millisecond,
+		}
+
+		reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+		t.Cleanup(func() {
+			require.NoError(t, reporter.Close())
+		})
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		defer cancel()
+
+		start := time.Now()
+		err = reporter.ReportEventSync(ctx, &v1alpha1.TelemetryEvent{
+			Name: "TestEvent",
+		})
+		require.Error(t, err)
+
+		// Fails within the handshake timeout, rather than the request timeout.
+		assert.Less(t, time.Since(start), 5*time.Second)
+		assert.NoError(t, ctx.Err())
+	})
+}
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package v1alpha1
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+const (
+	// MaxAttachmentSize is the maximum size of an attachment in bytes, before
+	// it is encoded.
+	MaxAttachmentSize = 4096
+	// The prefix of the value keys used to store attachments.
+	attachmentValueKeyPrefix = "attachment_"
+)
+
+// Attach adds a small named blob (eg. a configuration snippet, or a truncated
+// log) to the event's values. It is stored as a base64 encoded data URL (eg.
+// "data:text/plain;base64,...") under the key "attachment_<name>", replacing
+// any existing attachment with the same name. An error is returned if the
+// data is larger than MaxAttachmentSize.
+//
+// Attachments are ordinary values, so a reporter configured with a
+// MaxValueLength smaller than the encoded attachment will truncate it.
+func (e *TelemetryEvent) Attach(name, contentType string, data []byte) error {
+	if len(data) > MaxAttachmentSize {
+		return fmt.Errorf("attachment %q is too large: %d bytes exceeds the limit of %d bytes",
+			name, len(data), MaxAttachmentSize)
+	}
+
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	if e.Values == nil {
+		e.Values = make(map[string]string)
+	}
+
+	e.Values[attachmentValueKeyPrefix+name] = "data:" + contentType + ";base64," +
+		base64.StdEncoding.EncodeToString(data)
+
+	return nil
+}
+
+// Attachment returns the content type and data of the named attachment. An
+// error is returned if the event has no such attachment, or it is malformed.
+func (e *TelemetryEvent) Attachment(name string) (string, []byte, error) {
+	value, ok := e.Values[attachmentValueKeyPrefix+name]
+	if !ok {
+		return "", nil, fmt.Errorf("attachment %q not found", name)
+	}
+
+	value, isDataURL := strings.CutPrefix(value, "data:")
+	contentType, encoded, ok := strings.Cut(value, ";base64,")
+	if !isDataURL || !ok {
+		return "", nil, fmt.Errorf("attachment %q is malformed", name)
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", nil, fmt.Errorf("attachment %q is malformed: %w", name, err)
+	}
+
+	return contentType, data, nil
+}
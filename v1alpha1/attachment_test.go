@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package v1alpha1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetryEvent_Attach(t *testing.T) {
+	t.Run("Round Trip", func(t *testing.T) {
+		eventCh := make(chan *v1alpha1.TelemetryEvent, 1)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var event v1alpha1.TelemetryEvent
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+			eventCh <- &event
+		}))
+		t.Cleanup(server.Close)
+
+		config := []byte("listen: 0.0.0.0:8080\x00\xff")
+
+		event := &v1alpha1.TelemetryEvent{
+			Kind: v1alpha1.TelemetryEventKindError,
+			Name: "ConfigError",
+		}
+		require.NoError(t, event.Attach("config", "application/yaml", config))
+
+		client := v1alpha1.NewTelemetryEventClient(server.Client(), server.URL)
+		require.NoError(t, client.ReportEvent(context.Background(), event))
+
+		received := <-eventCh
+
+		contentType, data, err := received.Attachment("config")
+		require.NoError(t, err)
+
+		assert.Equal(t, "application/yaml", contentType)
+		assert.Equal(t, config, data)
+	})
+
+	t.Run("Too Large", func(t *testing.T) {
+		var event v1alpha1.TelemetryEvent
+
+		err := event.Attach("log", "text/plain", []byte(strings.Repeat("x", v1alpha1.MaxAttachmentSize+1)))
+		require.Error(t, err)
+
+		assert.Empty(t, event.Values)
+	})
+
+	t.Run("Missing", func(t *testing.T) {
+		event := v1alpha1.TelemetryEvent{
+			Values: map[string]string{
+				"attachment_log": "not a data URL",
+			},
+		}
+
+		_, _, err := event.Attachment("config")
+		require.Error(t, err)
+
+		_, _, err = event.Attachment("log")
+		require.Error(t, err)
+	})
+}
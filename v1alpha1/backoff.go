@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package v1alpha1
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// The default fraction of each delay that is randomized.
+const defaultBackoffJitter = 0.2
+
+// BackoffStrategy decides how long to wait before retrying a failed request.
+// A delay requested by the server (with a Retry-After header) takes
+// precedence.
+type BackoffStrategy interface {
+	// NextDelay returns the delay before the next retry, where attempt is the
+	// number of retries already made (zero before the first retry).
+	NextDelay(attempt int) time.Duration
+}
+
+// ConstantBackoff waits the same delay before every retry.
+type ConstantBackoff struct {
+	// Delay is the delay before each retry.
+	Delay time.Duration
+}
+
+func (b ConstantBackoff) NextDelay(attempt int) time.Duration {
+	return b.Delay
+}
+
+// LinearBackoff increases the delay by a fixed step after every retry.
+type LinearBackoff struct {
+	// Initial is the delay before the first retry.
+	Initial time.Duration
+	// Step is added to the delay after each retry.
+	Step time.Duration
+	// Max is the optional maximum delay.
+	Max time.Duration
+}
+
+func (b LinearBackoff) NextDelay(attempt int) time.Duration {
+	delay := b.Initial + time.Duration(max(attempt, 0))*b.Step
+	if delay < 0 {
+		// Overflowed.
+		delay = math.MaxInt64
+	}
+
+	return capDelay(delay, b.Max)
+}
+
+// ExponentialBackoff doubles the delay after every retry, optionally reducing
+// each delay by a random amount (jitter) so that clients that failed at the
+// same time don't all retry in lockstep.
+type ExponentialBackoff struct {
+	// Initial is the delay before the first retry.
+	Initial time.Duration
+	// Max is the optional maximum delay (before jitter is applied).
+	Max time.Duration
+	// Jitter is the maximum fraction, in the range [0, 1], of each delay that
+	// is randomly subtracted from it. Zero disables jitter.
+	Jitter float64
+}
+
+func (b ExponentialBackoff) NextDelay(attempt int) time.Duration {
+	delay := b.Initial
+	for i := 0; i < attempt && delay < math.MaxInt64/2; i++ {
+		delay *= 2
+	}
+
+	delay = capDelay(delay, b.Max)
+
+	if jitter := min(max(b.Jitter, 0), 1); jitter > 0 && delay > 0 {
+		delay -= time.Duration(rand.Float64() * jitter * float64(delay))
+	}
+
+	return delay
+}
+
+// capDelay limits delay to at most maxDelay, if positive.
+func capDelay(delay, maxDelay time.Duration) time.Duration {
+	if maxDelay > 0 && delay > maxDelay {
+		return maxDelay
+	}
+
+	return delay
+}
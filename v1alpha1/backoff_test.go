@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package v1alpha1_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackoffStrategies(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy v1alpha1.BackoffStrategy
+		want     []time.Duration
+	}{
+		{
+			name:     "Constant",
+			strategy: v1alpha1.ConstantBackoff{Delay: time.Second},
+			want:     []time.Duration{time.Second, time.Second, time.Second, time.Second},
+		},
+		{
+			name:     "Linear",
+			strategy: v1alpha1.LinearBackoff{Initial: time.Second, Step: 500 * time.Millisecond, Max: 2 * time.Second},
+			want:     []time.Duration{time.Second, 1500 * time.Millisecond, 2 * time.Second, 2 * time.Second},
+		},
+		{
+			name:     "Exponential",
+			strategy: v1alpha1.ExponentialBackoff{Initial: 100 * time.Millisecond, Max: time.Second},
+			want: []time.Duration{
+				100 * time.Millisecond,
+				200 * time.Millisecond,
+				400 * time.Millisecond,
+				800 * time.Millisecond,
+				time.Second,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var delays []time.Duration
+			for attempt := range tt.want {
+				delays = append(delays, tt.strategy.NextDelay(attempt))
+			}
+
+			assert.Equal(t, tt.want, delays)
+		})
+	}
+
+	t.Run("Exponential Overflow", func(t *testing.T) {
+		strategy := v1alpha1.ExponentialBackoff{Initial: time.Second}
+
+		assert.Positive(t, strategy.NextDelay(1000))
+	})
+
+	t.Run("Exponential Jitter", func(t *testing.T) {
+		strategy := v1alpha1.ExponentialBackoff{Initial: 100 * time.Millisecond, Jitter: 0.5}
+
+		for i := 0; i < 100; i++ {
+			delay := strategy.NextDelay(2)
+
+			assert.GreaterOrEqual(t, delay, 200*time.Millisecond)
+			assert.LessOrEqual(t, delay, 400*time.Millisecond)
+		}
+	})
+}
+
+func TestTelemetryEventClient_Backoff(t *testing.T) {
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusServiceUnavailable,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}),
+	}
+
+	strategy := &recordingBackoff{delay: time.Millisecond}
+
+	client := v1alpha1.NewTelemetryEventClient(httpClient, "http://telemetry.invalid", v1alpha1.ClientOptions{
+		MaxRetries: 3,
+		Backoff:    strategy,
+	})
+
+	require.Error(t, client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"}))
+
+	strategy.mu.Lock()
+	defer strategy.mu.Unlock()
+
+	assert.Equal(t, []int{0, 1, 2}, strategy.attempts)
+}
+
+// recordingBackoff is a constant backoff that records every attempt it was
+// asked for a delay.
+type recordingBackoff struct {
+	delay    time.Duration
+	mu       sync.Mutex
+	attempts []int
+}
+
+func (b *recordingBackoff) NextDelay(attempt int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.attempts = append(b.attempts, attempt)
+
+	return b.delay
+}
@@ -0,0 +1,65 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// BatchResponse is the body of a partial success (207 Multi-Status) response
+// to a batch of events, listing the events the server rejected. Events not
+// listed were accepted.
+type BatchResponse struct {
+	// Rejected is the list of rejected events.
+	Rejected []RejectedEvent `json:"rejected,omitempty"`
+}
+
+// RejectedEvent describes an event in a batch that the server rejected.
+type RejectedEvent struct {
+	// Index is the index of the event in the batch.
+	Index int `json:"index"`
+	// Reason is a description of why the event was rejected.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ParseBatchResponse parses a BatchResponse from the body of a response to a
+// batch of events, returning a *RejectedEventsError if any of the events were
+// rejected. An empty body means every event was accepted. It can be used as
+// ClientOptions.ValidateBatchResponse, by default it is only used for 207
+// Multi-Status responses.
+func ParseBatchResponse(resp *http.Response, events []*TelemetryEvent) error {
+	var batchResp BatchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&batchResp); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		return fmt.Errorf("failed to decode batch response: %w", err)
+	}
+
+	rejected := make(map[int]string, len(batchResp.Rejected))
+	for _, e := range batchResp.Rejected {
+		if e.Index < 0 || e.Index >= len(events) {
+			return fmt.Errorf("rejected event index out of range: %d", e.Index)
+		}
+
+		rejected[e.Index] = e.Reason
+	}
+
+	if len(rejected) == 0 {
+		return nil
+	}
+
+	return &RejectedEventsError{Rejected: rejected}
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package v1alpha1
+
+// EventBuilder is a fluent builder for telemetry events.
+type EventBuilder struct {
+	event TelemetryEvent
+}
+
+// NewEvent returns a builder for a new telemetry event with the given name.
+func NewEvent(name string) *EventBuilder {
+	return &EventBuilder{
+		event: TelemetryEvent{
+			Name: name,
+		},
+	}
+}
+
+// Kind sets the kind of event.
+func (b *EventBuilder) Kind(kind TelemetryEventKind) *EventBuilder {
+	b.event.Kind = kind
+	return b
+}
+
+// Message sets the message associated with the event.
+func (b *EventBuilder) Message(message string) *EventBuilder {
+	b.event.Message = message
+	return b
+}
+
+// ParentID sets the ID of the operation (or event) that caused the event.
+func (b *EventBuilder) ParentID(id string) *EventBuilder {
+	b.event.ParentID = id
+	return b
+}
+
+// CorrelationID sets the ID shared by the events of the same logical operation.
+func (b *EventBuilder) CorrelationID(id string) *EventBuilder {
+	b.event.CorrelationID = id
+	return b
+}
+
+// Value adds a value to the event, replacing any existing value with the same key.
+func (b *EventBuilder) Value(key, value string) *EventBuilder {
+	if b.event.Values == nil {
+		b.event.Values = make(map[string]string)
+	}
+
+	b.event.Values[key] = value
+	return b
+}
+
+// Tag adds one or more tags to the event.
+func (b *EventBuilder) Tag(tags ...string) *EventBuilder {
+	b.event.Tags = append(b.event.Tags, tags...)
+	return b
+}
+
+// Build returns the constructed event. Each call returns an independent copy,
+// so the builder can safely be reused to construct further events.
+func (b *EventBuilder) Build() *TelemetryEvent {
+	return b.event.DeepCopy()
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package v1alpha1_test
+
+import (
+	"testing"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventBuilder(t *testing.T) {
+	event := v1alpha1.NewEvent("TestEvent").
+		Kind(v1alpha1.TelemetryEventKindWarning).
+		Message("Something happened").
+		ParentID("parent").
+		CorrelationID("correlation").
+		Value("key1", "value1").
+		Value("key2", "value2").
+		Value("key1", "value3").
+		Tag("tag1").
+		Tag("tag2", "tag3").
+		Build()
+
+	assert.Equal(t, &v1alpha1.TelemetryEvent{
+		Kind:          v1alpha1.TelemetryEventKindWarning,
+		Name:          "TestEvent",
+		Message:       "Something happened",
+		ParentID:      "parent",
+		CorrelationID: "correlation",
+		Values: map[string]string{
+			"key1": "value3",
+			"key2": "value2",
+		},
+		Tags: []string{"tag1", "tag2", "tag3"},
+	}, event)
+}
+
+func TestEventBuilder_Independent(t *testing.T) {
+	builder := v1alpha1.NewEvent("TestEvent").
+		Value("key1", "value1").
+		Tag("tag1")
+
+	first := builder.Build()
+
+	// Reusing the builder should not affect previously built events.
+	builder.Value("key2", "value2").Tag("tag2")
+
+	second := builder.Build()
+
+	assert.Equal(t, map[string]string{"key1": "value1"}, first.Values)
+	assert.Equal(t, []string{"tag1"}, first.Tags)
+
+	assert.Equal(t, map[string]string{"key1": "value1", "key2": "value2"}, second.Values)
+	assert.Equal(t, []string{"tag1", "tag2"}, second.Tags)
+
+	// Modifying a built event should not affect the builder.
+	second.Values["key1"] = "modified"
+	second.Tags[0] = "modified"
+
+	third := builder.Build()
+	assert.Equal(t, "value1", third.Values["key1"])
+	assert.Equal(t, "tag1", third.Tags[0])
+}
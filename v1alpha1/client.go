@@ -12,35 +12,290 @@ package v1alpha1
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strings"
+	"time"
 )
 
+const (
+	// The default initial delay between retries.
+	defaultRetryBackoff = 500 * time.Millisecond
+	// The default maximum number of bytes of an error response body to capture.
+	defaultMaxErrorBodyBytes = 4 * 1024
+	// The default path of the health check endpoint.
+	defaultHealthCheckPath = "/healthz"
+)
+
+// ClientOptions are optional settings for the telemetry event client.
+type ClientOptions struct {
+	// MaxRetries is the maximum number of times a request that failed due to
+	// a transient error (eg. a connection reset, or a 503 response) is
+	// retried. Defaults to no retries.
+	MaxRetries int
+	// RetryBackoff is the delay before the first retry, it is doubled after
+	// each subsequent attempt (less up to 20% jitter). Defaults to 500ms. A
+	// delay requested by the server with a Retry-After header takes
+	// precedence. It is ignored if Backoff is set.
+	RetryBackoff time.Duration
+	// Backoff is an optional strategy deciding the delay before each retry.
+	// Defaults to an ExponentialBackoff starting at RetryBackoff.
+	Backoff BackoffStrategy
+	// RequestHook is an optional function called with each outgoing request
+	// just before it is sent. It may modify the request, or abort the send by
+	// returning an error.
+	RequestHook func(*http.Request) error
+	// ValidateResponse is an optional function called with each successful
+	// response. Returning an error marks the request as failed.
+	ValidateResponse func(*http.Response) error
+	// ValidateBatchResponse is an optional function called with each
+	// successful response to a batch of events, it is used instead of
+	// ValidateResponse for batches. Individual events can be marked as failed
+	// by returning a *RejectedEventsError. If unset, 207 Multi-Status
+	// responses are parsed with ParseBatchResponse.
+	ValidateBatchResponse func(*http.Response, []*TelemetryEvent) error
+	// StreamBatches streams batches of events to the server as
+	// newline-delimited JSON, rather than as a single JSON array. This keeps
+	// memory usage bounded regardless of the size of the batch. It requires a
+	// JSON ContentType.
+	StreamBatches bool
+	// Marshaler is an optional function used to encode each event. Defaults
+	// to json.Marshal.
+	Marshaler func(*TelemetryEvent) ([]byte, error)
+	// BatchMarshaler is an optional function used to encode a batch of
+	// events. Defaults to a JSON array of the events encoded by Marshaler,
+	// which is only valid with a JSON ContentType.
+	BatchMarshaler func([]*TelemetryEvent) ([]byte, error)
+	// RequestSigner is an optional function called with each outgoing request
+	// and its body, after any RequestHook, just before it is sent. It is
+	// intended to authenticate the request (eg. see HMACSigner). If set,
+	// streamed batches are buffered in memory so they can be signed.
+	RequestSigner func(req *http.Request, body []byte) error
+	// ContentType is the Content-Type header sent with events encoded by
+	// Marshaler (and batches encoded by BatchMarshaler). Defaults to
+	// application/json. Unless it is a JSON content type, batches can only be
+	// sent if BatchMarshaler is set, and can't be streamed.
+	ContentType string
+	// UserAgent is the User-Agent header sent with every request. Defaults to
+	// DefaultUserAgent().
+	UserAgent string
+	// HealthCheckPath is the path of the endpoint requested by Ping. Defaults
+	// to /healthz.
+	HealthCheckPath string
+	// DisableIdempotencyKeys disables the Idempotency-Key header. By default
+	// each request is sent with a random key (a UUID) that is reused when the
+	// request is retried, so that the server can deduplicate events if a
+	// response is lost after it processed the request.
+	DisableIdempotencyKeys bool
+	// MaxErrorBodyBytes is the maximum number of bytes of an error response
+	// body that are read and captured in the returned HTTPError, the rest is
+	// discarded. Defaults to 4KiB.
+	MaxErrorBodyBytes int64
+}
+
 type TelemetryEventClient struct {
-	httpClient *http.Client
-	baseURL    string
+	httpClient            *http.Client
+	baseURL               string
+	maxRetries            int
+	backoff               BackoffStrategy
+	requestHook           func(*http.Request) error
+	requestSigner         func(*http.Request, []byte) error
+	validateResponse      func(*http.Response) error
+	validateBatchResponse func(*http.Response, []*TelemetryEvent) error
+	streamBatches         bool
+	marshal               func(*TelemetryEvent) ([]byte, error)
+	marshalBatch          func([]*TelemetryEvent) ([]byte, error)
+	contentType           string
+	jsonContent           bool
+	userAgent             string
+	healthCheckPath       string
+	idempotencyKeys       bool
+	maxErrorBody          int64
 }
 
-func NewTelemetryEventClient(httpClient *http.Client, baseURL string) *TelemetryEventClient {
+// NewTelemetryEventClient creates a client that reports events to the
+// telemetry server at baseURL. The options are optional (only the first is
+// used), if omitted the defaults are used.
+func NewTelemetryEventClient(httpClient *http.Client, baseURL string, options ...ClientOptions) *TelemetryEventClient {
+	var opts ClientOptions
+	if len(options) > 0 {
+		opts = options[0]
+	}
+
+	backoff := opts.Backoff
+	if backoff == nil {
+		retryBackoff := opts.RetryBackoff
+		if retryBackoff <= 0 {
+			retryBackoff = defaultRetryBackoff
+		}
+
+		backoff = ExponentialBackoff{Initial: retryBackoff, Jitter: defaultBackoffJitter}
+	}
+
+	marshal := opts.Marshaler
+	if marshal == nil {
+		marshal = func(event *TelemetryEvent) ([]byte, error) {
+			return json.Marshal(event)
+		}
+	}
+
+	contentType := opts.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+
+	userAgent := opts.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent()
+	}
+
+	healthCheckPath := opts.HealthCheckPath
+	if healthCheckPath == "" {
+		healthCheckPath = defaultHealthCheckPath
+	}
+
+	maxErrorBody := opts.MaxErrorBodyBytes
+	if maxErrorBody <= 0 {
+		maxErrorBody = defaultMaxErrorBodyBytes
+	}
+
 	return &TelemetryEventClient{
-		httpClient: httpClient,
-		baseURL:    baseURL,
+		httpClient:            httpClient,
+		baseURL:               baseURL,
+		maxRetries:            opts.MaxRetries,
+		backoff:               backoff,
+		requestHook:           opts.RequestHook,
+		requestSigner:         opts.RequestSigner,
+		validateResponse:      opts.ValidateResponse,
+		validateBatchResponse: opts.ValidateBatchResponse,
+		streamBatches:         opts.StreamBatches,
+		marshal:               marshal,
+		marshalBatch:          opts.BatchMarshaler,
+		contentType:           contentType,
+		jsonContent:           IsJSONContentType(contentType),
+		userAgent:             userAgent,
+		healthCheckPath:       healthCheckPath,
+		idempotencyKeys:       !opts.DisableIdempotencyKeys,
+		maxErrorBody:          maxErrorBody,
 	}
 }
 
 func (c *TelemetryEventClient) ReportEvent(ctx context.Context, event *TelemetryEvent) error {
-	eventJSON, err := json.Marshal(event)
+	eventJSON, err := c.marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+		return fmt.Errorf("%w: %w", ErrMarshal, err)
+	}
+
+	return c.send(ctx, "/v1alpha1/events", c.contentType, bytesBody(eventJSON), c.validateResponse)
+}
+
+// ReportEvents reports a batch of events in a single request.
+func (c *TelemetryEventClient) ReportEvents(ctx context.Context, events []*TelemetryEvent) error {
+	validate := func(resp *http.Response) error {
+		if c.validateBatchResponse != nil {
+			return c.validateBatchResponse(resp, events)
+		}
+
+		// Some of the events were rejected.
+		if resp.StatusCode == http.StatusMultiStatus {
+			return ParseBatchResponse(resp, events)
+		}
+
+		if c.validateResponse != nil {
+			return c.validateResponse(resp)
+		}
+
+		return nil
+	}
+
+	if c.streamBatches {
+		if !c.jsonContent {
+			return fmt.Errorf("%w: streamed batches require a JSON content type, not %q", ErrMarshal, c.contentType)
+		}
+
+		return c.send(ctx, "/v1alpha1/events/stream", "application/x-ndjson", c.ndjsonBody(events), validate)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/v1alpha1/events", bytes.NewReader(eventJSON))
+	var (
+		eventsJSON []byte
+		err        error
+	)
+	switch {
+	case c.marshalBatch != nil:
+		eventsJSON, err = c.marshalBatch(events)
+	case c.jsonContent:
+		eventsJSON, err = c.marshalArray(events)
+	default:
+		return fmt.Errorf("%w: batches with content type %q require a BatchMarshaler", ErrMarshal, c.contentType)
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrMarshal, err)
+	}
+
+	return c.send(ctx, "/v1alpha1/events/batch", c.contentType, bytesBody(eventsJSON), validate)
+}
+
+// marshalArray encodes the events as a JSON array.
+func (c *TelemetryEventClient) marshalArray(events []*TelemetryEvent) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, event := range events {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		eventJSON, err := c.marshal(event)
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(eventJSON)
+	}
+	buf.WriteByte(']')
+
+	return buf.Bytes(), nil
+}
+
+// IsJSONContentType returns whether contentType is a JSON media type (eg.
+// application/json, or application/vnd.example+json).
+func IsJSONContentType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// Ping checks that the telemetry server is reachable, and that requests are
+// accepted (eg. that any credentials added by the RequestHook are valid), by
+// requesting its health check endpoint. It returns nil if the server
+// responds with a 2xx status code. Ping is never retried.
+func (c *TelemetryEventClient) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+c.healthCheckPath, nil)
 	if err != nil {
 		return fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+
+	if c.requestHook != nil {
+		if err := c.requestHook(req); err != nil {
+			return fmt.Errorf("request hook failed: %w", err)
+		}
+	}
+
+	if c.requestSigner != nil {
+		if err := c.requestSigner(req, nil); err != nil {
+			return fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -48,9 +303,222 @@ func (c *TelemetryEventClient) ReportEvent(ctx context.Context, event *Telemetry
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	if err := c.responseError(resp); err != nil {
+		return err
 	}
 
+	// Drain the body so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, c.maxErrorBody))
+
 	return nil
 }
+
+// requestBody is a replayable request body.
+type requestBody struct {
+	// getBody returns a new copy of the body, it is used for every attempt,
+	// and as http.Request.GetBody so that the body can be replayed on
+	// redirects.
+	getBody func() (io.ReadCloser, error)
+	// The length of the body, or -1 if unknown.
+	contentLength int64
+	// The complete body, or nil if it is streamed.
+	bytes []byte
+}
+
+// bytesBody returns a request body that replays the already marshaled b, so
+// that retries don't need to marshal the events again.
+func bytesBody(b []byte) requestBody {
+	return requestBody{
+		getBody: func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(b)), nil
+		},
+		contentLength: int64(len(b)),
+		bytes:         b,
+	}
+}
+
+// ndjsonBody returns a request body that streams the events as
+// newline-delimited JSON. Events are encoded as the body is read, so the
+// complete body is never held in memory.
+func (c *TelemetryEventClient) ndjsonBody(events []*TelemetryEvent) requestBody {
+	return requestBody{
+		getBody:       func() (io.ReadCloser, error) { return c.streamEvents(events), nil },
+		contentLength: -1,
+	}
+}
+
+// streamEvents returns a reader of the events encoded as newline-delimited
+// JSON, encoding each event as the reader is consumed.
+func (c *TelemetryEventClient) streamEvents(events []*TelemetryEvent) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		for _, event := range events {
+			eventJSON, err := c.marshal(event)
+			if err != nil {
+				pw.CloseWithError(fmt.Errorf("%w: %w", ErrMarshal, err))
+				return
+			}
+
+			// Fails if the request was aborted, unblocking the writer.
+			if _, err := pw.Write(append(eventJSON, '\n')); err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+
+		pw.Close()
+	}()
+
+	return pr
+}
+
+// bufferBody reads a streamed body into memory.
+func bufferBody(body requestBody) (requestBody, error) {
+	r, err := body.getBody()
+	if err != nil {
+		return requestBody{}, fmt.Errorf("failed to create request body: %w", err)
+	}
+	defer r.Close()
+
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return requestBody{}, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	return bytesBody(b), nil
+}
+
+func (c *TelemetryEventClient) send(ctx context.Context, path, contentType string, body requestBody, validate func(*http.Response) error) error {
+	// The signer needs the complete body.
+	if c.requestSigner != nil && body.bytes == nil {
+		var err error
+		body, err = bufferBody(body)
+		if err != nil {
+			return err
+		}
+	}
+
+	// The key is shared by every attempt, so that retries can be detected.
+	var idempotencyKey string
+	if c.idempotencyKeys {
+		idempotencyKey = newIdempotencyKey()
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := c.sendOnce(ctx, path, contentType, idempotencyKey, body, validate)
+		if err == nil || attempt >= c.maxRetries || !isRetryable(err) {
+			return err
+		}
+
+		delay := c.backoff.NextDelay(attempt)
+
+		// Honor the delay requested by the server, unless it would outlast
+		// the request.
+		var httpErr *HTTPError
+		if errors.As(err, &httpErr) && httpErr.RetryAfter > 0 {
+			delay = httpErr.RetryAfter
+
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (c *TelemetryEventClient) sendOnce(ctx context.Context, path, contentType, idempotencyKey string, body requestBody, validate func(*http.Response) error) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	reqBody, err := body.getBody()
+	if err != nil {
+		return fmt.Errorf("failed to create request body: %w", err)
+	}
+	// Release the body, even if the request is never sent.
+	defer reqBody.Close()
+
+	req.Body = reqBody
+	req.GetBody = body.getBody
+	req.ContentLength = body.contentLength
+
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", c.userAgent)
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	if c.requestHook != nil {
+		if err := c.requestHook(req); err != nil {
+			return fmt.Errorf("request hook failed: %w", err)
+		}
+	}
+
+	if c.requestSigner != nil {
+		if err := c.requestSigner(req, body.bytes); err != nil {
+			return fmt.Errorf("failed to sign request: %w", err)
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if err := c.responseError(resp); err != nil {
+		return err
+	}
+
+	if validate != nil {
+		if err := validate(resp); err != nil {
+			return fmt.Errorf("invalid response: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// responseError returns an *HTTPError if the response has a non-2xx status
+// code. At most maxErrorBody bytes of the body are captured.
+func (c *TelemetryEventClient) responseError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return nil
+	}
+
+	// Best effort, the body is only used to provide context.
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, c.maxErrorBody))
+
+	httpErr := &HTTPError{
+		StatusCode: resp.StatusCode,
+		Body:       strings.TrimSpace(string(body)),
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+		httpErr.RetryAfter, _ = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+	}
+
+	return httpErr
+}
+
+// newIdempotencyKey returns a random (version 4) UUID, or an empty string if
+// the system's random number generator fails.
+func newIdempotencyKey() string {
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return ""
+	}
+
+	uuid[6] = (uuid[6] & 0x0f) | 0x40 // Version 4.
+	uuid[8] = (uuid[8] & 0x3f) | 0x80 // RFC 4122 variant.
+
+	b := hex.EncodeToString(uuid[:])
+	return b[0:8] + "-" + b[8:12] + "-" + b[12:16] + "-" + b[16:20] + "-" + b[20:]
+}
@@ -0,0 +1,862 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package v1alpha1_test
+
+import (
+	"bufio"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTelemetryEventClient_Retry(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		wantAttempts int32
+	}{
+		{
+			name:         "DNS Not Found",
+			err:          &net.DNSError{Err: "no such host", Name: "telemetry.invalid", IsNotFound: true},
+			wantAttempts: 1,
+		},
+		{
+			name:         "DNS Timeout",
+			err:          &net.DNSError{Err: "i/o timeout", Name: "telemetry.invalid", IsTimeout: true},
+			wantAttempts: 3,
+		},
+		{
+			name:         "Certificate Invalid",
+			err:          &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}},
+			wantAttempts: 1,
+		},
+		{
+			name:         "Timeout",
+			err:          &timeoutError{},
+			wantAttempts: 3,
+		},
+		{
+			name:         "Connection Reset",
+			err:          &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET},
+			wantAttempts: 3,
+		},
+		{
+			name:         "Unexpected EOF",
+			err:          io.ErrUnexpectedEOF,
+			wantAttempts: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			httpClient := &http.Client{
+				Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					attempts.Add(1)
+					return nil, tt.err
+				}),
+			}
+
+			client := v1alpha1.NewTelemetryEventClient(httpClient, "http://telemetry.invalid", v1alpha1.ClientOptions{
+				MaxRetries:   2,
+				RetryBackoff: time.Millisecond,
+			})
+
+			err := client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"})
+			require.Error(t, err)
+
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestTelemetryEventClient_RetrySucceeds(t *testing.T) {
+	var attempts atomic.Int32
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			if attempts.Add(1) < 3 {
+				return nil, &timeoutError{}
+			}
+
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("")),
+			}, nil
+		}),
+	}
+
+	client := v1alpha1.NewTelemetryEventClient(httpClient, "http://telemetry.invalid", v1alpha1.ClientOptions{
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	})
+
+	require.NoError(t, client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"}))
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestTelemetryEventClient_RetryReusesBody(t *testing.T) {
+	var mu sync.Mutex
+	var payloads []string
+
+	// A mock server that fails twice, then succeeds.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mu.Lock()
+		payloads = append(payloads, string(body))
+		attempt := len(payloads)
+		mu.Unlock()
+
+		assert.Equal(t, int64(len(body)), r.ContentLength)
+
+		if attempt <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	var marshals atomic.Int32
+	client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+		Marshaler: func(event *v1alpha1.TelemetryEvent) ([]byte, error) {
+			marshals.Add(1)
+			return json.Marshal(event)
+		},
+	})
+
+	event := &v1alpha1.TelemetryEvent{
+		Name:    "TestEvent",
+		Message: strings.Repeat("x", 64*1024),
+	}
+
+	require.NoError(t, client.ReportEvent(context.Background(), event))
+
+	// The event should only have been marshaled once.
+	assert.Equal(t, int32(1), marshals.Load())
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Len(t, payloads, 3)
+
+	expected, err := json.Marshal(event)
+	require.NoError(t, err)
+
+	for _, payload := range payloads {
+		assert.Equal(t, string(expected), payload)
+	}
+}
+
+func TestTelemetryEventClient_RequestHook(t *testing.T) {
+	headerCh := make(chan http.Header, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headerCh <- r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("Modify", func(t *testing.T) {
+		var hookCalls atomic.Int32
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			RequestHook: func(req *http.Request) error {
+				hookCalls.Add(1)
+
+				assert.Equal(t, http.MethodPost, req.Method)
+				assert.Equal(t, server.URL+"/v1alpha1/events", req.URL.String())
+				assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+				req.Header.Set("X-Signature", "signed")
+				return nil
+			},
+		})
+
+		require.NoError(t, client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"}))
+		assert.Equal(t, int32(1), hookCalls.Load())
+
+		header := <-headerCh
+		assert.Equal(t, "signed", header.Get("X-Signature"))
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		errAbort := errors.New("abort")
+
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			MaxRetries:   2,
+			RetryBackoff: time.Millisecond,
+			RequestHook: func(req *http.Request) error {
+				return errAbort
+			},
+		})
+
+		err := client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"})
+		require.ErrorIs(t, err, errAbort)
+
+		select {
+		case <-headerCh:
+			t.Fatal("Expected the request to have been aborted")
+		default:
+		}
+	})
+}
+
+func TestTelemetryEventClient_StreamBatches(t *testing.T) {
+	const numEvents = 1000
+
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		assert.Equal(t, "/v1alpha1/events/stream", r.URL.Path)
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+
+		var names []string
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			var event v1alpha1.TelemetryEvent
+			require.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+
+			names = append(names, event.Name)
+		}
+		require.NoError(t, scanner.Err())
+
+		require.Len(t, names, numEvents)
+		for i, name := range names {
+			assert.Equal(t, "Event"+strconv.Itoa(i), name)
+		}
+
+		// Fail the first attempt to check the stream is replayed on retry.
+		if attempts.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+		MaxRetries:    1,
+		RetryBackoff:  time.Millisecond,
+		StreamBatches: true,
+	})
+
+	events := make([]*v1alpha1.TelemetryEvent, numEvents)
+	for i := range events {
+		events[i] = &v1alpha1.TelemetryEvent{Name: "Event" + strconv.Itoa(i)}
+	}
+
+	require.NoError(t, client.ReportEvents(context.Background(), events))
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestTelemetryEventClient_Marshaler(t *testing.T) {
+	bodyCh := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		bodyCh <- string(body)
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("Custom", func(t *testing.T) {
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			Marshaler: func(event *v1alpha1.TelemetryEvent) ([]byte, error) {
+				return []byte(`{"sentinel":"` + event.Name + `"}`), nil
+			},
+		})
+
+		require.NoError(t, client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"}))
+		assert.Equal(t, `{"sentinel":"TestEvent"}`, <-bodyCh)
+
+		require.NoError(t, client.ReportEvents(context.Background(), []*v1alpha1.TelemetryEvent{
+			{Name: "Event0"},
+			{Name: "Event1"},
+		}))
+		assert.Equal(t, `[{"sentinel":"Event0"},{"sentinel":"Event1"}]`, <-bodyCh)
+	})
+
+	t.Run("Binary", func(t *testing.T) {
+		marshal := func(event *v1alpha1.TelemetryEvent) ([]byte, error) {
+			return []byte(event.Name), nil
+		}
+
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			Marshaler:   marshal,
+			ContentType: "application/msgpack",
+			BatchMarshaler: func(events []*v1alpha1.TelemetryEvent) ([]byte, error) {
+				var names []string
+				for _, event := range events {
+					names = append(names, event.Name)
+				}
+				return []byte(strings.Join(names, "|")), nil
+			},
+		})
+
+		require.NoError(t, client.ReportEvents(context.Background(), []*v1alpha1.TelemetryEvent{
+			{Name: "Event0"},
+			{Name: "Event1"},
+		}))
+		assert.Equal(t, "Event0|Event1", <-bodyCh)
+
+		// Batches can't be framed as JSON without a BatchMarshaler.
+		client = v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			Marshaler:   marshal,
+			ContentType: "application/msgpack",
+		})
+
+		err := client.ReportEvents(context.Background(), []*v1alpha1.TelemetryEvent{{Name: "TestEvent"}})
+		require.ErrorIs(t, err, v1alpha1.ErrMarshal)
+
+		// Nor streamed as newline-delimited JSON.
+		client = v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			Marshaler:     marshal,
+			ContentType:   "application/msgpack",
+			StreamBatches: true,
+		})
+
+		err = client.ReportEvents(context.Background(), []*v1alpha1.TelemetryEvent{{Name: "TestEvent"}})
+		require.ErrorIs(t, err, v1alpha1.ErrMarshal)
+
+		select {
+		case <-bodyCh:
+			t.Fatal("Expected no request to have been sent")
+		default:
+		}
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		errMarshal := errors.New("marshal failed")
+
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			Marshaler: func(event *v1alpha1.TelemetryEvent) ([]byte, error) {
+				return nil, errMarshal
+			},
+		})
+
+		err := client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"})
+		require.ErrorIs(t, err, errMarshal)
+
+		err = client.ReportEvents(context.Background(), []*v1alpha1.TelemetryEvent{{Name: "TestEvent"}})
+		require.ErrorIs(t, err, errMarshal)
+
+		select {
+		case <-bodyCh:
+			t.Fatal("Expected no request to have been sent")
+		default:
+		}
+	})
+}
+
+func TestIsJSONContentType(t *testing.T) {
+	assert.True(t, v1alpha1.IsJSONContentType("application/json"))
+	assert.True(t, v1alpha1.IsJSONContentType("application/json; charset=utf-8"))
+	assert.True(t, v1alpha1.IsJSONContentType("application/vnd.example+json"))
+	assert.False(t, v1alpha1.IsJSONContentType("application/msgpack"))
+	assert.False(t, v1alpha1.IsJSONContentType(""))
+}
+
+func TestTelemetryEventClient_UserAgent(t *testing.T) {
+	userAgentCh := make(chan string, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgentCh <- r.UserAgent()
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("Default", func(t *testing.T) {
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL)
+
+		require.NoError(t, client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"}))
+
+		userAgent := <-userAgentCh
+		assert.Equal(t, v1alpha1.DefaultUserAgent(), userAgent)
+		assert.True(t, strings.HasPrefix(userAgent, "dpeckett-telemetry/"))
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			UserAgent: "myapp/1.2.3",
+		})
+
+		require.NoError(t, client.ReportEvents(context.Background(), []*v1alpha1.TelemetryEvent{{Name: "TestEvent"}}))
+		assert.Equal(t, "myapp/1.2.3", <-userAgentCh)
+	})
+}
+
+func TestTelemetryEventClient_StatusCodes(t *testing.T) {
+	tests := []struct {
+		statusCode int
+		wantErr    bool
+	}{
+		{statusCode: http.StatusOK},
+		{statusCode: http.StatusAccepted},
+		{statusCode: http.StatusNoContent},
+		{statusCode: 299},
+		{statusCode: http.StatusMultipleChoices, wantErr: true},
+		{statusCode: http.StatusBadRequest, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.Itoa(tt.statusCode), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+			}))
+			t.Cleanup(server.Close)
+
+			client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL)
+
+			err := client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"})
+			if tt.wantErr {
+				var httpErr *v1alpha1.HTTPError
+				require.ErrorAs(t, err, &httpErr)
+				assert.Equal(t, tt.statusCode, httpErr.StatusCode)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestTelemetryEventClient_HTTPError(t *testing.T) {
+	tests := []struct {
+		name          string
+		statusCode    int
+		body          string
+		wantAttempts  int32
+		wantRetryable bool
+	}{
+		{
+			name:         "Bad Request",
+			statusCode:   http.StatusBadRequest,
+			body:         "invalid event",
+			wantAttempts: 1,
+		},
+		{
+			name:          "Service Unavailable",
+			statusCode:    http.StatusServiceUnavailable,
+			body:          "try again later",
+			wantAttempts:  3,
+			wantRetryable: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body + "\n"))
+			}))
+			t.Cleanup(server.Close)
+
+			client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+				MaxRetries:   2,
+				RetryBackoff: time.Millisecond,
+			})
+
+			err := client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"})
+			require.Error(t, err)
+
+			var httpErr *v1alpha1.HTTPError
+			require.True(t, errors.As(err, &httpErr))
+
+			assert.Equal(t, tt.statusCode, httpErr.StatusCode)
+			assert.Equal(t, tt.body, httpErr.Body)
+			assert.Equal(t, tt.wantRetryable, httpErr.Retryable())
+			assert.Contains(t, err.Error(), strconv.Itoa(tt.statusCode))
+
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+		})
+	}
+}
+
+func TestTelemetryEventClient_MaxErrorBodyBytes(t *testing.T) {
+	// A server that responds with a huge error body.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(strings.Repeat("x", 1024*1024)))
+	}))
+	t.Cleanup(server.Close)
+
+	t.Run("Default", func(t *testing.T) {
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL)
+
+		err := client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"})
+
+		var httpErr *v1alpha1.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+
+		assert.Equal(t, strings.Repeat("x", 4*1024), httpErr.Body)
+	})
+
+	t.Run("Configured", func(t *testing.T) {
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			MaxErrorBodyBytes: 16,
+		})
+
+		err := client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"})
+
+		var httpErr *v1alpha1.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+
+		assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode)
+		assert.Equal(t, strings.Repeat("x", 16), httpErr.Body)
+	})
+}
+
+func TestTelemetryEventClient_RetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter func() string
+		minDelay   time.Duration
+	}{
+		{
+			name:       "Seconds",
+			retryAfter: func() string { return "1" },
+			minDelay:   time.Second,
+		},
+		{
+			name: "HTTP Date",
+			retryAfter: func() string {
+				// HTTP dates only have a resolution of one second.
+				return time.Now().Add(2 * time.Second).UTC().Format(http.TimeFormat)
+			},
+			minDelay: time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var attemptTimes []time.Time
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				attemptTimes = append(attemptTimes, time.Now())
+				first := len(attemptTimes) == 1
+				mu.Unlock()
+
+				if first {
+					w.Header().Set("Retry-After", tt.retryAfter())
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}))
+			t.Cleanup(server.Close)
+
+			client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+				MaxRetries:   1,
+				RetryBackoff: time.Millisecond,
+			})
+
+			require.NoError(t, client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"}))
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			require.Len(t, attemptTimes, 2)
+			assert.GreaterOrEqual(t, attemptTimes[1].Sub(attemptTimes[0]), tt.minDelay)
+		})
+	}
+
+	t.Run("Exceeds Deadline", func(t *testing.T) {
+		var attempts atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		t.Cleanup(server.Close)
+
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			MaxRetries:   1,
+			RetryBackoff: time.Millisecond,
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		t.Cleanup(cancel)
+
+		// Gives up immediately, rather than waiting for the deadline.
+		start := time.Now()
+		err := client.ReportEvent(ctx, &v1alpha1.TelemetryEvent{Name: "TestEvent"})
+
+		var httpErr *v1alpha1.HTTPError
+		require.True(t, errors.As(err, &httpErr))
+		assert.Equal(t, time.Hour, httpErr.RetryAfter)
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, int32(1), attempts.Load())
+	})
+}
+
+func TestTelemetryEventClient_RequestSigner(t *testing.T) {
+	secret := []byte("shared-secret")
+
+	type request struct {
+		signature string
+		body      []byte
+	}
+	requestCh := make(chan request, 2)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		requestCh <- request{
+			signature: r.Header.Get("X-Signature"),
+			body:      body,
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	for _, streamBatches := range []bool{false, true} {
+		client := v1alpha1.NewTelemetryEventClient(http.DefaultClient, server.URL, v1alpha1.ClientOptions{
+			RequestSigner: v1alpha1.HMACSigner("X-Signature", secret),
+			StreamBatches: streamBatches,
+		})
+
+		require.NoError(t, client.ReportEvent(context.Background(), &v1alpha1.TelemetryEvent{Name: "TestEvent"}))
+		require.NoError(t, client.ReportEvents(context.Background(), []*v1alpha1.TelemetryEvent{
+			{Name: "Event0"},
+			{Name: "Event1"},
+		}))
+
+		for i := 0; i < 2; i++ {
+			req := <-requestCh
+			require.NotEmpty(t, req.body)
+
+			mac := hmac.New(sha256.New, secret)
+			mac.Write(req.body)
+			assert.Equal(t, hex.EncodeToString(mac.Sum(nil)), req.signature)
+		}
+	}
+}
+
+type roundTripperFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+type timeoutError struct{}
+
+func (e *timeoutError) Error() string   { return "i/o timeout" }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
+
+func TestTelemetryEventClient_Ping(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusOK)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "GET", r.Method)
+
+		if r.URL.Path != "/healthz" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		w.WriteHeader(int(status.Load()))
+	}))
+	t.Cleanup(server.Close)
+
+	client := v1alpha1.NewTelemetryEventClient(server.Client(), server.URL, v1alpha1.ClientOptions{
+		RequestHook: func(req *http.Request) error {
+			req.Header.Set("Authorization", "Bearer token")
+			return nil
+		},
+	})
+
+	ctx := context.Background()
+
+	t.Run("Healthy", func(t *testing.T) {
+		require.NoError(t, client.Ping(ctx))
+	})
+
+	t.Run("Unhealthy", func(t *testing.T) {
+		status.Store(http.StatusServiceUnavailable)
+		t.Cleanup(func() { status.Store(http.StatusOK) })
+
+		var httpErr *v1alpha1.HTTPError
+		require.ErrorAs(t, client.Ping(ctx), &httpErr)
+		assert.Equal(t, http.StatusServiceUnavailable, httpErr.StatusCode)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		client := v1alpha1.NewTelemetryEventClient(server.Client(), server.URL)
+
+		var httpErr *v1alpha1.HTTPError
+		require.ErrorAs(t, client.Ping(ctx), &httpErr)
+		assert.Equal(t, http.StatusUnauthorized, httpErr.StatusCode)
+	})
+
+	t.Run("Health Check Path", func(t *testing.T) {
+		client := v1alpha1.NewTelemetryEventClient(server.Client(), server.URL, v1alpha1.ClientOptions{
+			HealthCheckPath: "/status",
+			RequestHook: func(req *http.Request) error {
+				req.Header.Set("Authorization", "Bearer token")
+				return nil
+			},
+		})
+
+		require.Error(t, client.Ping(ctx))
+	})
+}
+
+func TestTelemetryEventClient_IdempotencyKey(t *testing.T) {
+	var mu sync.Mutex
+	keys := map[string][]string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var event v1alpha1.TelemetryEvent
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&event))
+
+		mu.Lock()
+		keys[event.Name] = append(keys[event.Name], r.Header.Get("Idempotency-Key"))
+		attempts := len(keys[event.Name])
+		mu.Unlock()
+
+		// Fail the first attempts, as if the response was lost.
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(server.Close)
+
+	client := v1alpha1.NewTelemetryEventClient(server.Client(), server.URL, v1alpha1.ClientOptions{
+		MaxRetries:   2,
+		RetryBackoff: time.Millisecond,
+	})
+
+	ctx := context.Background()
+	require.NoError(t, client.ReportEvent(ctx, &v1alpha1.TelemetryEvent{Name: "Event1"}))
+	require.NoError(t, client.ReportEvent(ctx, &v1alpha1.TelemetryEvent{Name: "Event2"}))
+
+	mu.Lock()
+	uuidRegexp := `^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`
+
+	// The same key is sent with every attempt of an event.
+	require.Len(t, keys["Event1"], 3)
+	assert.Regexp(t, uuidRegexp, keys["Event1"][0])
+	assert.Equal(t, keys["Event1"][0], keys["Event1"][1])
+	assert.Equal(t, keys["Event1"][0], keys["Event1"][2])
+
+	// But differs across events.
+	require.Len(t, keys["Event2"], 3)
+	assert.Regexp(t, uuidRegexp, keys["Event2"][0])
+	assert.NotEqual(t, keys["Event1"][0], keys["Event2"][0])
+	mu.Unlock()
+
+	t.Run("Disabled", func(t *testing.T) {
+		client := v1alpha1.NewTelemetryEventClient(server.Client(), server.URL, v1alpha1.ClientOptions{
+			MaxRetries:             2,
+			RetryBackoff:           time.Millisecond,
+			DisableIdempotencyKeys: true,
+		})
+
+		require.NoError(t, client.ReportEvent(ctx, &v1alpha1.TelemetryEvent{Name: "Event3"}))
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		assert.Equal(t, []string{"", "", ""}, keys["Event3"])
+	})
+}
+
+func TestTelemetryEventClient_PartialSuccess(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMultiStatus)
+		_, _ = w.Write([]byte(`{"rejected":[{"index":1,"reason":"invalid event"}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := v1alpha1.NewTelemetryEventClient(server.Client(), server.URL)
+
+	err := client.ReportEvents(context.Background(), []*v1alpha1.TelemetryEvent{
+		{Name: "Event1"},
+		{Name: "Event2"},
+	})
+
+	var rejectedErr *v1alpha1.RejectedEventsError
+	require.ErrorAs(t, err, &rejectedErr)
+	assert.Equal(t, map[int]string{1: "invalid event"}, rejectedErr.Rejected)
+}
+
+func TestParseBatchResponse(t *testing.T) {
+	events := []*v1alpha1.TelemetryEvent{{Name: "Event1"}, {Name: "Event2"}}
+
+	parse := func(body string) error {
+		return v1alpha1.ParseBatchResponse(&http.Response{
+			StatusCode: http.StatusMultiStatus,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, events)
+	}
+
+	t.Run("Empty", func(t *testing.T) {
+		require.NoError(t, parse(""))
+		require.NoError(t, parse(`{"rejected":[]}`))
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		var rejectedErr *v1alpha1.RejectedEventsError
+		require.ErrorAs(t, parse(`{"rejected":[{"index":0,"reason":"a"},{"index":1}]}`), &rejectedErr)
+		assert.Equal(t, map[int]string{0: "a", 1: ""}, rejectedErr.Rejected)
+	})
+
+	t.Run("Out Of Range", func(t *testing.T) {
+		err := parse(`{"rejected":[{"index":2}]}`)
+		require.Error(t, err)
+
+		var rejectedErr *v1alpha1.RejectedEventsError
+		assert.False(t, errors.As(err, &rejectedErr))
+	})
+
+	t.Run("Malformed", func(t *testing.T) {
+		require.Error(t, parse(`{"rejected":`))
+	})
+}
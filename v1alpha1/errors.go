@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package v1alpha1
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMarshal is wrapped by the errors returned when an event could not be
+// marshaled.
+var ErrMarshal = errors.New("failed to marshal event")
+
+// HTTPError is returned when the telemetry server responds with an unexpected
+// status code.
+type HTTPError struct {
+	// StatusCode is the HTTP status code returned by the server.
+	StatusCode int
+	// Body is a snippet of the response body, it may be truncated.
+	Body string
+	// RetryAfter is the delay requested by the server (with a Retry-After
+	// header) before the request is retried, or zero if none was requested.
+	RetryAfter time.Duration
+}
+
+func (e *HTTPError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("unexpected status code: %d: %s", e.StatusCode, e.Body)
+}
+
+// Retryable returns true if the request may succeed if retried.
+func (e *HTTPError) Retryable() bool {
+	switch e.StatusCode {
+	case http.StatusRequestTimeout, http.StatusTooManyRequests:
+		return true
+	case http.StatusNotImplemented, http.StatusHTTPVersionNotSupported:
+		return false
+	default:
+		return e.StatusCode >= http.StatusInternalServerError
+	}
+}
+
+// parseRetryAfter parses the value of a Retry-After header, in either the
+// delta-seconds or HTTP-date form, returning the delay relative to now.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	return max(date.Sub(now), 0), true
+}
+
+// RejectedEventsError is returned when the telemetry server rejected some of
+// the events in a batch.
+type RejectedEventsError struct {
+	// Rejected maps the index of each rejected event in the batch to the
+	// reason it was rejected.
+	Rejected map[int]string
+}
+
+func (e *RejectedEventsError) Error() string {
+	indices := make([]int, 0, len(e.Rejected))
+	for i := range e.Rejected {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+
+	reasons := make([]string, 0, len(indices))
+	for _, i := range indices {
+		reasons = append(reasons, fmt.Sprintf("%d: %s", i, e.Rejected[i]))
+	}
+
+	return fmt.Sprintf("%d events rejected (%s)", len(e.Rejected), strings.Join(reasons, ", "))
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package v1alpha1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The JSON Schema dialect of the generated schema.
+const jsonSchemaDialect = "https://json-schema.org/draft/2020-12/schema"
+
+// The permitted values of the enumerated string types.
+var jsonSchemaEnums = map[reflect.Type][]any{
+	reflect.TypeFor[TelemetryEventKind](): {
+		TelemetryEventKindInfo,
+		TelemetryEventKindWarning,
+		TelemetryEventKindError,
+	},
+	reflect.TypeFor[TelemetryEventPriority](): {
+		TelemetryEventPriorityNormal,
+		TelemetryEventPriorityHigh,
+	},
+}
+
+var jsonSchema = sync.OnceValue(func() []byte {
+	g := &schemaGenerator{defs: map[string]any{}}
+
+	schema := g.objectSchema(reflect.TypeFor[TelemetryEvent]())
+	schema["$schema"] = jsonSchemaDialect
+	schema["title"] = "TelemetryEvent"
+	schema["$defs"] = g.defs
+
+	schemaJSON, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		panic(fmt.Sprintf("failed to marshal JSON schema: %v", err))
+	}
+
+	return schemaJSON
+})
+
+// JSONSchema returns a JSON Schema (draft 2020-12) describing a
+// TelemetryEvent (and its StackFrames), generated from the Go types. It can
+// be used by servers to validate incoming events.
+func JSONSchema() []byte {
+	return slices.Clone(jsonSchema())
+}
+
+// WriteJSONSchema writes the JSON Schema returned by JSONSchema to w.
+func WriteJSONSchema(w io.Writer) error {
+	_, err := w.Write(jsonSchema())
+	return err
+}
+
+// schemaGenerator generates JSON schemas from Go types, collecting the
+// schemas of nested structs as definitions.
+type schemaGenerator struct {
+	defs map[string]any
+}
+
+func (g *schemaGenerator) schema(t reflect.Type) map[string]any {
+	if enum, ok := jsonSchemaEnums[t]; ok {
+		return map[string]any{"type": "string", "enum": enum}
+	}
+
+	if t == reflect.TypeFor[time.Time]() {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schema(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int32:
+		return map[string]any{"type": "integer", "minimum": math.MinInt32, "maximum": math.MaxInt32}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		return map[string]any{"type": "array", "items": g.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schema(t.Elem())}
+	case reflect.Struct:
+		if _, ok := g.defs[t.Name()]; !ok {
+			g.defs[t.Name()] = g.objectSchema(t)
+		}
+
+		return map[string]any{"$ref": "#/$defs/" + t.Name()}
+	default:
+		panic(fmt.Sprintf("unsupported type in JSON schema: %s", t))
+	}
+}
+
+// objectSchema returns the schema of a struct, with a property for each of
+// its JSON encoded fields.
+func (g *schemaGenerator) objectSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		} else if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = g.schema(field.Type)
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
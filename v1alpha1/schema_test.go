@@ -0,0 +1,103 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package v1alpha1_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONSchema(t *testing.T) {
+	const schemaURL = "https://example.com/telemetry-event.json"
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(v1alpha1.JSONSchema()))
+	require.NoError(t, err)
+
+	compiler := jsonschema.NewCompiler()
+	require.NoError(t, compiler.AddResource(schemaURL, doc))
+
+	schema, err := compiler.Compile(schemaURL)
+	require.NoError(t, err)
+
+	validate := func(t *testing.T, eventJSON []byte) error {
+		t.Helper()
+
+		inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(eventJSON))
+		require.NoError(t, err)
+
+		return schema.Validate(inst)
+	}
+
+	t.Run("Valid", func(t *testing.T) {
+		timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		event := v1alpha1.NewEvent("TestEvent").
+			Kind(v1alpha1.TelemetryEventKindError).
+			Message("Something went wrong").
+			Value("key", "value").
+			Tag("tag").
+			ParentID("parent").
+			Build()
+		event.SchemaVersion = v1alpha1.SchemaVersion
+		event.SessionID = "session"
+		event.Timestamp = &timestamp
+		event.Priority = v1alpha1.TelemetryEventPriorityHigh
+		event.StackTrace = []*v1alpha1.StackFrame{
+			{File: "main.go", Function: "main.main", Line: 42, Column: 7},
+		}
+
+		eventJSON, err := json.Marshal(event)
+		require.NoError(t, err)
+
+		assert.NoError(t, validate(t, eventJSON))
+
+		// An empty event is valid, as every field is optional.
+		assert.NoError(t, validate(t, []byte(`{}`)))
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			eventJSON string
+		}{
+			{name: "Unknown Kind", eventJSON: `{"kind":"fatal"}`},
+			{name: "Unknown Field", eventJSON: `{"name":"TestEvent","unknown":true}`},
+			{name: "Non-String Value", eventJSON: `{"values":{"key":1}}`},
+			{name: "Malformed Timestamp", eventJSON: `{"timestamp":42}`},
+			{name: "Invalid Stack Frame", eventJSON: `{"stack_trace":[{"line":"42"}]}`},
+			{name: "Line Out Of Range", eventJSON: `{"stack_trace":[{"line":4294967296}]}`},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				assert.Error(t, validate(t, []byte(tt.eventJSON)))
+			})
+		}
+	})
+}
+
+func TestWriteJSONSchema(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, v1alpha1.WriteJSONSchema(&buf))
+
+	assert.Equal(t, v1alpha1.JSONSchema(), buf.Bytes())
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &schema))
+
+	assert.Equal(t, "https://json-schema.org/draft/2020-12/schema", schema["$schema"])
+	assert.Contains(t, schema["$defs"], "StackFrame")
+}
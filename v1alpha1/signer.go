@@ -0,0 +1,31 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package v1alpha1
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+)
+
+// HMACSigner returns a request signer (see ClientOptions.RequestSigner) that
+// sets the given header to the hex encoded HMAC-SHA256 of the request body,
+// keyed with the shared secret.
+func HMACSigner(header string, secret []byte) func(req *http.Request, body []byte) error {
+	return func(req *http.Request, body []byte) error {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(body)
+
+		req.Header.Set(header, hex.EncodeToString(mac.Sum(nil)))
+
+		return nil
+	}
+}
@@ -11,6 +11,9 @@ package v1alpha1
 
 import "time"
 
+// SchemaVersion is the version of the telemetry event schema defined by this package.
+const SchemaVersion = "v1alpha1"
+
 // TelemetryEventKind represents the kind of event.
 type TelemetryEventKind string
 
@@ -23,14 +26,36 @@ const (
 	TelemetryEventKindError TelemetryEventKind = "error"
 )
 
+// TelemetryEventPriority represents the importance of an event.
+type TelemetryEventPriority string
+
+const (
+	// The event has normal priority (the default).
+	TelemetryEventPriorityNormal TelemetryEventPriority = ""
+	// The event has high priority, and should be sent even when the client
+	// is under load.
+	TelemetryEventPriorityHigh TelemetryEventPriority = "high"
+)
+
 type TelemetryEvent struct {
+	// The version of the event schema used by the client.
+	SchemaVersion string `json:"schema_version,omitempty"`
 	// The session ID associated with the event. The session id is short-lived and not persisted.
 	// It is only used to link events together (as there might be a relationship between them).
 	SessionID string `json:"session_id,omitempty"`
+	// The optional ID of the operation (or event) that caused this event, to
+	// explicitly link events into causal chains.
+	ParentID string `json:"parent_id,omitempty"`
+	// The optional ID shared by every event relating to the same logical
+	// operation (eg. a request), across sessions and services.
+	CorrelationID string `json:"correlation_id,omitempty"`
 	// Timestamp when the event occurred.
 	Timestamp *time.Time `json:"timestamp,omitempty"`
 	// The kind of event.
 	Kind TelemetryEventKind `json:"kind,omitempty"`
+	// The priority of the event. If unset, error events are treated as high
+	// priority.
+	Priority TelemetryEventPriority `json:"priority,omitempty"`
 	// The name of the event.
 	Name string `json:"name,omitempty"`
 	// A message associated with the event.
@@ -53,3 +78,50 @@ type StackFrame struct {
 	// The column number in the line where the error occurred.
 	Column int32 `json:"column,omitempty"`
 }
+
+// DeepCopy returns a deep copy of the event.
+func (e *TelemetryEvent) DeepCopy() *TelemetryEvent {
+	if e == nil {
+		return nil
+	}
+
+	out := *e
+
+	if e.Timestamp != nil {
+		timestamp := *e.Timestamp
+		out.Timestamp = &timestamp
+	}
+
+	if e.Values != nil {
+		out.Values = make(map[string]string, len(e.Values))
+		for k, v := range e.Values {
+			out.Values[k] = v
+		}
+	}
+
+	if e.StackTrace != nil {
+		out.StackTrace = make([]*StackFrame, len(e.StackTrace))
+		for i, frame := range e.StackTrace {
+			if frame != nil {
+				frameCopy := *frame
+				out.StackTrace[i] = &frameCopy
+			}
+		}
+	}
+
+	if e.Tags != nil {
+		out.Tags = append([]string(nil), e.Tags...)
+	}
+
+	return &out
+}
+
+// HighPriority returns whether the event is high priority, either explicitly
+// or because it is an error.
+func (e *TelemetryEvent) HighPriority() bool {
+	if e.Priority == TelemetryEventPriorityNormal {
+		return e.Kind == TelemetryEventKindError
+	}
+
+	return e.Priority == TelemetryEventPriorityHigh
+}
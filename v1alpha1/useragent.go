@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package v1alpha1
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+const modulePath = "github.com/dpeckett/telemetry"
+
+var defaultUserAgent = sync.OnceValue(func() string {
+	return "dpeckett-telemetry/" + moduleVersion()
+})
+
+// DefaultUserAgent returns the default User-Agent sent with telemetry
+// requests, eg. "dpeckett-telemetry/v0.1.0".
+func DefaultUserAgent() string {
+	return defaultUserAgent()
+}
+
+// moduleVersion returns the version of this module linked into the binary.
+func moduleVersion() string {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "devel"
+	}
+
+	version := bi.Main.Version
+	if bi.Main.Path != modulePath {
+		version = ""
+		for _, dep := range bi.Deps {
+			if dep.Path == modulePath {
+				version = dep.Version
+				if dep.Replace != nil && dep.Replace.Version != "" {
+					version = dep.Replace.Version
+				}
+				break
+			}
+		}
+	}
+
+	// Eg. "(devel)" when built from a local checkout.
+	if version == "" || version[0] == '(' {
+		return "devel"
+	}
+
+	return version
+}
@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package v1alpha1
+
+import (
+	"strconv"
+	"time"
+)
+
+// Event values are strings, the helpers below set typed values in a
+// canonical format so that they can be reliably parsed by the server.
+
+// IntValue sets an integer value on the event, formatted in base 10.
+func IntValue(event *TelemetryEvent, key string, value int64) {
+	setValue(event, key, strconv.FormatInt(value, 10))
+}
+
+// BoolValue sets a boolean value on the event, formatted as "true" or "false".
+func BoolValue(event *TelemetryEvent, key string, value bool) {
+	setValue(event, key, strconv.FormatBool(value))
+}
+
+// FloatValue sets a floating point value on the event, formatted with the
+// fewest digits that exactly represent it (eg. "0.1", "1e+21", or "NaN").
+func FloatValue(event *TelemetryEvent, key string, value float64) {
+	setValue(event, key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// TimeValue sets a time value on the event, formatted as RFC 3339 (with
+// nanosecond precision, if needed) in UTC.
+func TimeValue(event *TelemetryEvent, key string, value time.Time) {
+	setValue(event, key, value.UTC().Format(time.RFC3339Nano))
+}
+
+func setValue(event *TelemetryEvent, key, value string) {
+	if event.Values == nil {
+		event.Values = make(map[string]string)
+	}
+
+	event.Values[key] = value
+}
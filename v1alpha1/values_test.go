@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 Damian Peckett <damian@pecke.tt>.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package v1alpha1_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedValues(t *testing.T) {
+	var event v1alpha1.TelemetryEvent
+
+	v1alpha1.IntValue(&event, "int", 42)
+	v1alpha1.IntValue(&event, "negative_int", -7)
+	v1alpha1.BoolValue(&event, "true", true)
+	v1alpha1.BoolValue(&event, "false", false)
+	v1alpha1.FloatValue(&event, "float", 0.1)
+	v1alpha1.FloatValue(&event, "whole_float", 3)
+	v1alpha1.FloatValue(&event, "large_float", 1e21)
+	v1alpha1.FloatValue(&event, "nan", math.NaN())
+	v1alpha1.FloatValue(&event, "inf", math.Inf(-1))
+	v1alpha1.TimeValue(&event, "time", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	v1alpha1.TimeValue(&event, "local_time", time.Date(2024, 1, 2, 3, 4, 5, 600000000, time.FixedZone("AEST", 10*60*60)))
+
+	assert.Equal(t, map[string]string{
+		"int":          "42",
+		"negative_int": "-7",
+		"true":         "true",
+		"false":        "false",
+		"float":        "0.1",
+		"whole_float":  "3",
+		"large_float":  "1e+21",
+		"nan":          "NaN",
+		"inf":          "-Inf",
+		"time":         "2024-01-02T03:04:05Z",
+		"local_time":   "2024-01-01T17:04:05.6Z",
+	}, event.Values)
+}
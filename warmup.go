@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+
+package telemetry
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/dpeckett/telemetry/v1alpha1"
+)
+
+// warmup suppresses the noisy events that are typically reported during startup.
+type warmup struct {
+	startedAt    time.Time
+	period       time.Duration
+	events       int64
+	bypassErrors bool
+	seen         atomic.Int64
+}
+
+func newWarmup(startedAt time.Time, conf Configuration) *warmup {
+	return &warmup{
+		startedAt:    startedAt,
+		period:       conf.WarmupPeriod,
+		events:       int64(conf.WarmupEvents),
+		bypassErrors: conf.ReportErrorsDuringWarmup,
+	}
+}
+
+// suppress returns true if the event was reported during the warmup period and
+// should be dropped.
+func (w *warmup) suppress(event *v1alpha1.TelemetryEvent, now time.Time) bool {
+	if w.events <= 0 && w.period <= 0 {
+		return false
+	}
+
+	if w.bypassErrors && event.Kind == v1alpha1.TelemetryEventKindError {
+		return false
+	}
+
+	if w.events > 0 && w.seen.Add(1) <= w.events {
+		return true
+	}
+
+	if w.period > 0 {
+		if d, _ := elapsed(w.startedAt, now); d < w.period {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: MPL-2.0
+/*
+ * Copyright (C) 2024 The Noisy Sockets Authors.
+ *
+ * This Source Code Form is subject to the terms of the Mozilla Public
+ * License, v. 2.0. If a copy of the MPL was not distributed with this
+ * file, You can obtain one at http://mozilla.org/MPL/2.0/.
+ */
+package telemetry_test
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/dpeckett/telemetry"
+	"github.com/dpeckett/telemetry/v1alpha1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReporter_WarmupEvents(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	// Create a new telemetry reporter that suppresses the first two events.
+	conf := telemetry.Configuration{
+		BaseURL:      server.URL,
+		WarmupEvents: 2,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	for _, name := range []string{"First", "Second", "Third"} {
+		reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+			Name: name,
+		})
+	}
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "Third", event.Name)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+
+	select {
+	case event := <-eventCh:
+		t.Fatalf("Expected no further telemetry events, but got: %v", event)
+	default:
+	}
+}
+
+func TestReporter_WarmupPeriod(t *testing.T) {
+	// Start a mock telemetry server.
+	server, eventCh := mockTelemetryServer(t)
+	t.Cleanup(server.Close)
+
+	clock := &fakeClock{now: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	// Create a new telemetry reporter that suppresses events for the first minute.
+	conf := telemetry.Configuration{
+		BaseURL:                  server.URL,
+		WarmupPeriod:             time.Minute,
+		ReportErrorsDuringWarmup: true,
+		Clock:                    clock,
+	}
+
+	ctx := context.Background()
+	reporter := telemetry.NewReporter(ctx, slog.Default(), conf)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "DuringWarmup",
+	})
+
+	// Errors bypass warmup suppression.
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Kind: v1alpha1.TelemetryEventKindError,
+		Name: "ErrorDuringWarmup",
+	})
+
+	event := receiveEvent(t, eventCh)
+	assert.Equal(t, "ErrorDuringWarmup", event.Name)
+
+	clock.Advance(time.Minute)
+
+	reporter.ReportEvent(&v1alpha1.TelemetryEvent{
+		Name: "AfterWarmup",
+	})
+
+	event = receiveEvent(t, eventCh)
+	assert.Equal(t, "AfterWarmup", event.Name)
+
+	// Shutdown the reporter to ensure graceful exit.
+	require.NoError(t, reporter.Shutdown(ctx))
+}